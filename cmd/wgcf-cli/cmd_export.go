@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+	"github.com/ArchiveNetwork/wgcf-cli/utils"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [account files...]",
+	Short: "Export accounts as a WireGuard app tunnel bundle",
+	Long:  "Export accounts as a zip of tunnel configs importable by the WireGuard apps. By default the account from --config is exported.",
+	Run:   export,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().String("wireguard-zip", "", "zip file to write the tunnel configs to")
+	exportCmd.Flags().Bool("all-endpoints", false, "export a tunnel per endpoint type ('domain'/'ipv4'/'ipv6') instead of only the default endpoint")
+	exportCmd.MarkFlagRequired("wireguard-zip")
+}
+
+func export(cmd *cobra.Command, args []string) {
+	zipPath, _ := cmd.Flags().GetString("wireguard-zip")
+	allEndpoints, _ := cmd.Flags().GetBool("all-endpoints")
+	if path.Ext(zipPath) != ".zip" {
+		ExitDefault(errors.New("WireGuard tunnel bundle must be a '.zip' file"))
+	}
+
+	accounts := args
+	if len(accounts) == 0 {
+		accounts = []string{configPath}
+	}
+
+	var tunnels []utils.Tunnel
+	for _, account := range accounts {
+		var resStruct C.Response
+		defaults := utils.DefaultGenerateOptions()
+		resStruct.GenerateDefaults = &defaults
+		if err := json.Unmarshal(utils.ReadConfig(account), &resStruct); err != nil {
+			ExitDefault(fmt.Errorf("%s: %w", account, err))
+		}
+		if resStruct.GenerateDefaults == nil {
+			resStruct.GenerateDefaults = &defaults
+		}
+		options, err := utils.NormalizeOptions(*resStruct.GenerateDefaults)
+		if err != nil {
+			ExitDefault(fmt.Errorf("%s: %w", account, err))
+		}
+		name := resStruct.Name
+		if name == "" {
+			name = strings.TrimSuffix(path.Base(account), path.Ext(account))
+		}
+		tunnels = append(tunnels, utils.Tunnel{Name: name, Response: resStruct, Options: options})
+	}
+
+	body, err := utils.GenWireGuardZip(tunnels, allEndpoints)
+	if err != nil {
+		ExitDefault(err)
+	}
+	askOutputOverwrite(zipPath)
+	if err = os.WriteFile(zipPath, body, 0600); err != nil {
+		ExitDefault(err)
+	}
+	fmt.Printf("Export %d account(s) to WireGuard tunnel bundle '%s' successfully\n", len(tunnels), zipPath)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	C "github.com/ArchiveNetwork/wgcf-cli/constant"
@@ -16,10 +17,21 @@ import (
 
 var generateCmd = &cobra.Command{
 	Use:       "generate",
-	Short:     "Generate a xray/sing-box/wg-quick config",
+	Short:     "Generate a xray/sing-box/wg-quick/clash config",
 	Run:       generate,
 	Args:      cobra.OnlyValidArgs,
-	ValidArgs: []string{"--xray", "--xray-module", "--xray-endpoint", "--xray-tag", "--xray-indent-width", "--sing-box", "--wg", "--wg-quick", "--output-file"},
+	ValidArgs: []string{"--xray", "--xray-module", "--xray-endpoint", "--xray-tag", "--xray-indent-width", "--xray-sections", "--xray-inbound", "--xray-listen", "--xray-port", "--xray-auth", "--full", "--sing-box", "--sing-box-version", "--sing-box-tag", "--sing-box-profile", "--sing-box-inbound", "--wg", "--wg-quick", "--wg-quick-routing", "--wg-quick-table", "--wg-quick-fwmark", "--wg-quick-firewall", "--kill-switch", "--clash", "--mihomo", "--clash-name", "--clash-profile", "--wg-setconf", "--systemd-networkd", "--networkd", "--systemd-networkd-interface", "--networkmanager", "--nm", "--networkmanager-interface", "--openwrt", "--openwrt-interface", "--openwrt-batch", "--surge", "--surge-name", "--loon", "--loon-name", "--wireproxy", "--wireproxy-socks5", "--wireproxy-socks5-auth", "--wireproxy-http", "--wireproxy-http-auth", "--wireproxy-tunnel", "--routeros", "--mikrotik", "--routeros-interface", "--gluetun", "--docker-compose", "--docker-compose-service", "--kubernetes", "--k8s", "--kubernetes-name", "--kubernetes-namespace", "--kubernetes-key", "--share-link", "--share-link-dialect", "--share-link-name", "--amneziawg", "--awg", "--amneziawg-preset", "--amneziawg-jc", "--amneziawg-jmin", "--amneziawg-jmax", "--amneziawg-s1", "--amneziawg-s2", "--amneziawg-h1", "--amneziawg-h2", "--amneziawg-h3", "--amneziawg-h4", "--http-proxy", "--http-proxy-target", "--http-proxy-tag", "--netns", "--netns-name", "--netns-interface", "--mtu", "--keepalive", "--dns", "--allowed-ips", "--include", "--include-file", "--exclude", "--exclude-file", "--family", "--apply-policy", "--fallback-resolver", "--endpoint", "--port", "--save-defaults", "--validate", "--qr", "--qr-file", "--qr-invert", "--output-file"},
+}
+
+// generatorAliases maps a generator to an additional flag that enables it
+var generatorAliases = map[E.GeneratorType]string{
+	E.WgQuick:        "wg",
+	E.Clash:          "mihomo",
+	E.Networkd:       "networkd",
+	E.NetworkManager: "nm",
+	E.RouterOS:       "mikrotik",
+	E.Kubernetes:     "k8s",
+	E.AmneziaWG:      "awg",
 }
 
 func init() {
@@ -27,29 +39,120 @@ func init() {
 	generateCmd.Flags().Bool(asString(E.Xray), false, "generate a xray config")
 	generateCmd.Flags().Bool(asString(E.SingBox), false, "generate a sing-box config")
 	generateCmd.Flags().Bool(asString(E.WgQuick), false, "generate a wg-quick config")
-	generateCmd.Flags().Bool("wg", false, "see --"+asString(E.WgQuick))
+	generateCmd.Flags().Bool(asString(E.Clash), false, "generate a clash meta (mihomo) config")
+	generateCmd.Flags().Bool(asString(E.WgSetconf), false, "generate a plain wg(8) setconf config")
+	generateCmd.Flags().Bool(asString(E.Networkd), false, "generate systemd-networkd .netdev and .network files")
+	generateCmd.Flags().Bool(asString(E.NetworkManager), false, "generate a NetworkManager keyfile")
+	generateCmd.Flags().Bool(asString(E.OpenWrt), false, "generate an OpenWrt UCI network config")
+	generateCmd.Flags().Bool(asString(E.Surge), false, "generate a surge config")
+	generateCmd.Flags().Bool(asString(E.Loon), false, "generate a loon config")
+	generateCmd.Flags().Bool(asString(E.Wireproxy), false, "generate a wireproxy config")
+	generateCmd.Flags().Bool(asString(E.RouterOS), false, "generate a MikroTik RouterOS v7 script")
+	generateCmd.Flags().Bool(asString(E.Gluetun), false, "generate a gluetun env file")
+	generateCmd.Flags().Bool(asString(E.Compose), false, "generate a docker-compose gluetun service")
+	generateCmd.Flags().Bool(asString(E.Kubernetes), false, "generate a Kubernetes Secret containing the wg-quick config")
+	generateCmd.Flags().Bool(asString(E.ShareLink), false, "generate wireguard:// share links, one per endpoint type")
+	generateCmd.Flags().Bool(asString(E.AmneziaWG), false, "generate an AmneziaWG config")
+	generateCmd.Flags().Bool(asString(E.HTTPProxy), false, "generate a config using the HTTP proxy service of the account instead of WireGuard. The service usually has a private address, only reachable through a connected WARP tunnel")
+	generateCmd.Flags().Bool(asString(E.Netns), false, "generate a script which runs the tunnel inside a network namespace with up/down/exec commands")
+	for generator, alias := range generatorAliases {
+		generateCmd.Flags().Bool(alias, false, "see --"+asString(generator))
+	}
 
+	defaults := utils.DefaultGenerateOptions()
+	generateCmd.Flags().Int("mtu", defaults.MTU, "tunnel MTU of the generated config")
+	generateCmd.Flags().Int("keepalive", defaults.Keepalive, "persistent keepalive interval in seconds. 0 disables keepalive")
+	generateCmd.Flags().StringSlice("dns", defaults.DNS, "DNS servers of the generated config, none by default. 'none' clears the DNS servers saved by --save-defaults, 'gateway' uses the Cloudflare Gateway DoH/DoT endpoints of a Zero Trust account")
+	generateCmd.Flags().StringSlice("allowed-ips", defaults.AllowedIPs, "AllowedIPs of the generated config")
+	generateCmd.Flags().StringSlice("include", nil, "CIDRs to route through the tunnel instead of --allowed-ips")
+	generateCmd.Flags().StringArray("include-file", nil, "list file of CIDRs to route through the tunnel, one per line. Can be repeated")
+	generateCmd.Flags().StringSlice("exclude", nil, "CIDRs to keep off the tunnel, 'private' stands for the private and link-local ranges, 'none' clears the excluded CIDRs saved by --save-defaults")
+	generateCmd.Flags().StringArray("exclude-file", nil, "list file of CIDRs to keep off the tunnel, one per line. Can be repeated")
+	generateCmd.Flags().String("family", defaults.Family, "address family of the tunnel. Supported values: '"+strings.Join(utils.AddressFamilies, "'/'")+"'")
+	generateCmd.Flags().Bool("apply-policy", false, "keep the split tunnel exclusions of the account Zero Trust policy off the tunnel")
+	generateCmd.Flags().String("fallback-resolver", defaults.FallbackResolver, "resolver of the Zero Trust fallback domains, 'local' or an IP address")
+	generateCmd.Flags().String("endpoint", defaults.Endpoint, "endpoint to connect to. Supported values: 'domain'/'ipv4'/'ipv6'/'auto'/any 'host:port'. 'auto' picks an IP of the enabled address families")
+	generateCmd.Flags().Int("port", 0, "endpoint port between 1 and 65535. 0 keeps the port of the selected endpoint, the first port of the account endpoint by default")
+	generateCmd.Flags().String(asString(E.Xray)+"-endpoint", "", "endpoint type to use")
+	generateCmd.Flags().MarkDeprecated(asString(E.Xray)+"-endpoint", "use --endpoint instead")
+	generateCmd.Flags().Bool("save-defaults", false, "store the generation options in the account file as defaults for later runs")
+
+	generateCmd.Flags().Bool("validate", true, "check the generated config before writing it (xray/sing-box/wg-quick/wg-setconf/amneziawg)")
 	generateCmd.Flags().String("output-file", "default", "output file name. Supported values: 'default'/'stdout'/any file path")
+	generateCmd.Flags().Bool("qr", false, "print the output as a QR code in the terminal instead of writing it. wg-quick output is encoded without the wg-quick hooks for the WireGuard apps")
+	generateCmd.Flags().String("qr-file", "", "write the output as a QR code image instead of writing it. Supported formats: '.png'/'.svg'")
+	generateCmd.Flags().Bool("qr-invert", false, "invert terminal QR code colors for terminals with a light background")
+	generateCmd.Flags().Bool("full", false, "generate a complete runnable config instead of a single outbound (xray/sing-box/clash)")
 	generateCmd.Flags().String(asString(E.Xray)+"-module", "", "xray top-level config module ('inbounds' as example). By default generate no top-level module")
 	generateCmd.Flags().String(asString(E.Xray)+"-tag", "wireguard", "'Tag' field of xray config")
 	generateCmd.Flags().Uint8(asString(E.Xray)+"-indent-width", 4, "indentation size for xray config")
-	generateCmd.Flags().String(asString(E.Xray)+"-endpoint", "domain", "endpoint type to use. Supported values: 'domain'/'ip_v4'/'ip_v6'")
+	generateCmd.Flags().StringSlice(asString(E.Xray)+"-sections", utils.XraySections, "sections of a full xray config (--full) to generate besides the wireguard outbound")
+	generateCmd.Flags().String(asString(E.Xray)+"-inbound", "socks", "inbound protocol of a full xray config. Supported values: 'socks'/'http'")
+	generateCmd.Flags().String(asString(E.Xray)+"-listen", "127.0.0.1", "inbound listen address of a full xray config")
+	generateCmd.Flags().Uint16(asString(E.Xray)+"-port", 10808, "inbound port of a full xray config")
+	generateCmd.Flags().String(asString(E.Xray)+"-auth", "", "inbound credentials of a full xray config in 'user:pass' format. By default no authentication")
+
+	generateCmd.Flags().String(asString(E.SingBox)+"-version", "1.11", "target sing-box version. Versions before 1.11 get a legacy wireguard outbound instead of an endpoint")
+	generateCmd.Flags().String(asString(E.SingBox)+"-tag", "wireguard-out", "'tag' field of sing-box config")
+	generateCmd.Flags().Bool(asString(E.SingBox)+"-profile", false, "generate a full sing-box profile with inbound, dns and route. By default generate only the wireguard object")
+	generateCmd.Flags().String(asString(E.SingBox)+"-inbound", "mixed", "inbound of sing-box profile. Supported values: 'mixed'/'tun'")
+
+	routing := utils.DefaultWgQuickRouting()
+	generateCmd.Flags().String(asString(E.WgQuick)+"-routing", routing.Mode, "routing mode of wg-quick and amneziawg configs. Supported values: '"+strings.Join(utils.WgQuickRoutingModes, "'/'")+"'")
+	generateCmd.Flags().Uint32(asString(E.WgQuick)+"-table", routing.Table, "routing table of the 'fwmark'/'policy' wg-quick routing modes")
+	generateCmd.Flags().Uint32(asString(E.WgQuick)+"-fwmark", routing.FwMark, "firewall mark of the 'fwmark' wg-quick routing mode")
+	generateCmd.Flags().String(asString(E.WgQuick)+"-firewall", routing.Firewall, "backend of the wg-quick marking rules. Supported values: '"+strings.Join(utils.WgQuickFirewalls, "'/'")+"'")
+
+	generateCmd.Flags().Bool("kill-switch", false, "reject egress outside of the tunnel except to the endpoint and LAN in wg-quick and amneziawg configs, needs a 'full'/'ipv4'/'ipv6' routing mode")
+
+	generateCmd.Flags().String(asString(E.Clash)+"-name", "warp", "proxy name of clash config")
+	generateCmd.Flags().Bool(asString(E.Clash)+"-profile", false, "generate a full clash profile with a proxy group and rules. By default generate only the 'proxies' list")
+
+	generateCmd.Flags().String(asString(E.Networkd)+"-interface", "warp", "interface name of systemd-networkd config")
+	generateCmd.Flags().String(asString(E.NetworkManager)+"-interface", "warp", "connection and interface name of NetworkManager keyfile")
+
+	generateCmd.Flags().String(asString(E.OpenWrt)+"-interface", "warp", "interface name of OpenWrt config")
+	generateCmd.Flags().Bool(asString(E.OpenWrt)+"-batch", false, "generate a shell script applying the config with 'uci batch' instead of /etc/config/network sections")
+
+	for _, generator := range []E.GeneratorType{E.Surge, E.Loon} {
+		generateCmd.Flags().String(asString(generator)+"-name", "WARP", "proxy name of "+asString(generator)+" config")
+	}
+
+	generateCmd.Flags().String(asString(E.Wireproxy)+"-socks5", "127.0.0.1:40000", "SOCKS5 proxy bind address of wireproxy config. Empty value disables the proxy")
+	generateCmd.Flags().String(asString(E.Wireproxy)+"-socks5-auth", "", "SOCKS5 proxy credentials in 'user:pass' format. By default no authentication")
+	generateCmd.Flags().String(asString(E.Wireproxy)+"-http", "", "HTTP proxy bind address of wireproxy config. By default no HTTP proxy")
+	generateCmd.Flags().String(asString(E.Wireproxy)+"-http-auth", "", "HTTP proxy credentials in 'user:pass' format. By default no authentication")
+	generateCmd.Flags().StringArray(asString(E.Wireproxy)+"-tunnel", nil, "TCP port forward through the tunnel in 'bind_address=target_address' format. Can be repeated")
+
+	generateCmd.Flags().String(asString(E.RouterOS)+"-interface", "warp", "interface, routing table and routing mark name of RouterOS script")
+
+	generateCmd.Flags().String(asString(E.Compose)+"-service", "gluetun", "service and container name of docker-compose service")
+	generateCmd.Flags().String(asString(E.Kubernetes)+"-name", "warp", "name of Kubernetes Secret")
+	generateCmd.Flags().String(asString(E.Kubernetes)+"-namespace", "", "namespace of Kubernetes Secret. By default no namespace")
+	generateCmd.Flags().String(asString(E.Kubernetes)+"-key", "warp.conf", "data key of the wg-quick config in Kubernetes Secret")
+
+	generateCmd.Flags().String(asString(E.ShareLink)+"-dialect", "v2rayn", "share link dialect. Supported values: '"+strings.Join(utils.ShareLinkDialects, "'/'")+"'")
+	generateCmd.Flags().String(asString(E.ShareLink)+"-name", "WARP", "name of share links, suffixed with the endpoint type")
+
+	generateCmd.Flags().String(asString(E.AmneziaWG)+"-preset", "light", "AmneziaWG obfuscation preset. Supported values: 'light'/'medium'/'heavy'")
+	for _, param := range []string{"jc", "jmin", "jmax", "s1", "s2"} {
+		generateCmd.Flags().Int(asString(E.AmneziaWG)+"-"+param, 0, "AmneziaWG "+param+" parameter, overrides the preset")
+	}
+	for _, param := range []string{"h1", "h2", "h3", "h4"} {
+		generateCmd.Flags().Uint32(asString(E.AmneziaWG)+"-"+param, 0, "AmneziaWG "+param+" parameter, overrides the preset")
+	}
+
+	generateCmd.Flags().String(asString(E.HTTPProxy)+"-target", "pac", "HTTP proxy output. Supported values: '"+strings.Join(utils.HTTPProxyTargets, "'/'")+"'")
+	generateCmd.Flags().String(asString(E.HTTPProxy)+"-tag", "warp-http", "tag or proxy name of HTTP proxy outbound")
+
+	generateCmd.Flags().String(asString(E.Netns)+"-name", "warp", "name of the network namespace")
+	generateCmd.Flags().String(asString(E.Netns)+"-interface", "wgcf", "name of the WireGuard interface inside the network namespace")
 }
 
 func asString[V fmt.Stringer](object V) string {
 	return V.String(object)
 }
 
-func countTrue(args ...bool) uint {
-	var true_count uint = 0
-	for _, v := range args {
-		if v {
-			true_count += 1
-		}
-	}
-	return true_count
-}
-
 func askOutputOverwrite(path string) {
 	if _, err := os.Stat(path); !os.IsNotExist(err) {
 		var input string
@@ -71,6 +174,38 @@ func getDefaultFilePath(generator E.GeneratorType) string {
 		return baseName + ".sing-box.json"
 	case E.WgQuick:
 		return baseName + ".ini"
+	case E.Clash:
+		return baseName + ".clash.yaml"
+	case E.WgSetconf:
+		return baseName + ".conf"
+	case E.Networkd:
+		return baseName + ".netdev"
+	case E.NetworkManager:
+		return baseName + ".nmconnection"
+	case E.OpenWrt:
+		return baseName + ".uci"
+	case E.Surge:
+		return baseName + ".surge.conf"
+	case E.Loon:
+		return baseName + ".loon.conf"
+	case E.Wireproxy:
+		return baseName + ".wireproxy.conf"
+	case E.RouterOS:
+		return baseName + ".rsc"
+	case E.Gluetun:
+		return baseName + ".gluetun.env"
+	case E.Compose:
+		return baseName + ".compose.yaml"
+	case E.Kubernetes:
+		return baseName + ".secret.yaml"
+	case E.ShareLink:
+		return baseName + ".links.txt"
+	case E.AmneziaWG:
+		return baseName + ".awg.conf"
+	case E.HTTPProxy:
+		return baseName + ".proxy.pac"
+	case E.Netns:
+		return baseName + ".netns.sh"
 	}
 	return ""
 }
@@ -98,85 +233,308 @@ func generate(cmd *cobra.Command, args []string) {
 	}
 
 	var resStruct C.Response
+	var files []utils.GeneratedFile
+	fullConfig, _ := cmd.Flags().GetBool("full")
 	body := utils.ReadConfig(configPath)
+	defaults := utils.DefaultGenerateOptions()
+	resStruct.GenerateDefaults = &defaults
 	err = json.Unmarshal(body, &resStruct)
 	if err != nil {
 		ExitDefault(err)
 	}
+	rawOptions, err := detectGenerateOptions(cmd, resStruct, generator)
+	if err != nil {
+		ExitDefault(err)
+	}
+	options, err := utils.NormalizeOptions(rawOptions)
+	if err != nil {
+		ExitDefault(err)
+	}
+	if generator != E.HTTPProxy {
+		if _, _, err = utils.SelectEndpoint(resStruct, options); err != nil {
+			ExitDefault(err)
+		}
+	}
+	if options.GatewayDNS {
+		if _, _, err = utils.GatewayDNS(resStruct); err != nil {
+			ExitDefault(err)
+		}
+	}
+	if options.ApplyPolicy {
+		options, err = applyPolicy(cmd, resStruct, options, generator)
+		if err != nil {
+			ExitDefault(err)
+		}
+	}
 
 	switch generator {
 	case E.Xray:
 		confModule, _ := cmd.Flags().GetString(asString(E.Xray) + "-module")
 		tag, _ := cmd.Flags().GetString(asString(E.Xray) + "-tag")
 		indentWidth, _ := cmd.Flags().GetUint8(asString(E.Xray) + "-indent-width")
-		endpointType, err := detectEndpointType(cmd)
+		if fullConfig {
+			var scaffold utils.XrayScaffold
+			scaffold.Sections, _ = cmd.Flags().GetStringSlice(asString(E.Xray) + "-sections")
+			scaffold.Inbound, _ = cmd.Flags().GetString(asString(E.Xray) + "-inbound")
+			scaffold.Listen, _ = cmd.Flags().GetString(asString(E.Xray) + "-listen")
+			scaffold.Port, _ = cmd.Flags().GetUint16(asString(E.Xray) + "-port")
+			scaffold.Auth, _ = cmd.Flags().GetString(asString(E.Xray) + "-auth")
+			body, err = utils.GenXrayFull(resStruct, options, tag, indentWidth, scaffold)
+		} else {
+			body, err = utils.GenXray(resStruct, options, tag, confModule, indentWidth)
+		}
 		if err != nil {
 			ExitDefault(err)
 		}
-
-		body, err = utils.GenXray(resStruct, tag, confModule, indentWidth, endpointType)
+	case E.SingBox:
+		version, _ := cmd.Flags().GetString(asString(E.SingBox) + "-version")
+		tag, _ := cmd.Flags().GetString(asString(E.SingBox) + "-tag")
+		fullProfile, _ := cmd.Flags().GetBool(asString(E.SingBox) + "-profile")
+		fullProfile = fullProfile || fullConfig
+		inbound, _ := cmd.Flags().GetString(asString(E.SingBox) + "-inbound")
+		body, err = utils.GenSing(resStruct, options, tag, version, fullProfile, inbound)
 		if err != nil {
 			ExitDefault(err)
 		}
-	case E.SingBox:
-		body, err = utils.GenSing(resStruct)
 	case E.WgQuick:
-		body, err = utils.GenWgQuick(resStruct)
+		routing := detectWgQuickRouting(cmd)
+		if options, err = routing.Normalize(options); err != nil {
+			ExitDefault(err)
+		}
+		body, err = utils.GenWgQuick(resStruct, options, routing)
+	case E.Clash:
+		name, _ := cmd.Flags().GetString(asString(E.Clash) + "-name")
+		fullProfile, _ := cmd.Flags().GetBool(asString(E.Clash) + "-profile")
+		fullProfile = fullProfile || fullConfig
+		body, err = utils.GenClash(resStruct, options, name, fullProfile)
+		if err != nil {
+			ExitDefault(err)
+		}
+	case E.WgSetconf:
+		body, err = utils.GenWgSetconf(resStruct, options)
+	case E.Networkd:
+		name, _ := cmd.Flags().GetString(asString(E.Networkd) + "-interface")
+		files, err = utils.GenNetworkd(resStruct, options, name)
+	case E.NetworkManager:
+		name, _ := cmd.Flags().GetString(asString(E.NetworkManager) + "-interface")
+		body, err = utils.GenNetworkManager(resStruct, options, name)
+	case E.OpenWrt:
+		name, _ := cmd.Flags().GetString(asString(E.OpenWrt) + "-interface")
+		batch, _ := cmd.Flags().GetBool(asString(E.OpenWrt) + "-batch")
+		if batch {
+			body, err = utils.GenOpenWrtBatch(resStruct, options, name)
+			files = []utils.GeneratedFile{{Extension: ".sh", Body: body}}
+		} else {
+			body, err = utils.GenOpenWrt(resStruct, options, name)
+		}
+		if err != nil {
+			ExitDefault(err)
+		}
+	case E.Surge, E.Loon:
+		name, _ := cmd.Flags().GetString(asString(generator) + "-name")
+		if generator == E.Surge {
+			body, err = utils.GenSurge(resStruct, options, name)
+		} else {
+			body, err = utils.GenLoon(resStruct, options, name)
+		}
+		if err != nil {
+			ExitDefault(err)
+		}
+	case E.Wireproxy:
+		var proxyOptions utils.WireproxyOptions
+		proxyOptions.Socks5, _ = cmd.Flags().GetString(asString(E.Wireproxy) + "-socks5")
+		proxyOptions.Socks5Auth, _ = cmd.Flags().GetString(asString(E.Wireproxy) + "-socks5-auth")
+		proxyOptions.HTTP, _ = cmd.Flags().GetString(asString(E.Wireproxy) + "-http")
+		proxyOptions.HTTPAuth, _ = cmd.Flags().GetString(asString(E.Wireproxy) + "-http-auth")
+		proxyOptions.Tunnels, _ = cmd.Flags().GetStringArray(asString(E.Wireproxy) + "-tunnel")
+		body, err = utils.GenWireproxy(resStruct, options, proxyOptions)
+		if err != nil {
+			ExitDefault(err)
+		}
+	case E.RouterOS:
+		name, _ := cmd.Flags().GetString(asString(E.RouterOS) + "-interface")
+		body, err = utils.GenRouterOS(resStruct, options, name)
+		if err != nil {
+			ExitDefault(err)
+		}
+	case E.Gluetun, E.Compose:
+		if generator == E.Gluetun {
+			body, err = utils.GenGluetun(resStruct, options)
+		} else {
+			service, _ := cmd.Flags().GetString(asString(E.Compose) + "-service")
+			body, err = utils.GenCompose(resStruct, options, service)
+		}
+		if err != nil {
+			ExitDefault(err)
+		}
+	case E.Kubernetes:
+		name, _ := cmd.Flags().GetString(asString(E.Kubernetes) + "-name")
+		namespace, _ := cmd.Flags().GetString(asString(E.Kubernetes) + "-namespace")
+		key, _ := cmd.Flags().GetString(asString(E.Kubernetes) + "-key")
+		body, err = utils.GenKubernetesSecret(resStruct, options, name, namespace, key)
+	case E.ShareLink:
+		dialect, _ := cmd.Flags().GetString(asString(E.ShareLink) + "-dialect")
+		name, _ := cmd.Flags().GetString(asString(E.ShareLink) + "-name")
+		body, err = utils.GenShareLinks(resStruct, options, dialect, name)
+	case E.AmneziaWG:
+		params, err := detectAmneziaWGParams(cmd)
+		if err != nil {
+			ExitDefault(err)
+		}
+		if !params.CloudflareCompatible() {
+			fmt.Fprintln(os.Stderr, "Warn: S1/S2 other than 0 or H1-H4 other than 1-4 are not accepted by Cloudflare WARP")
+		}
+
+		routing := detectWgQuickRouting(cmd)
+		if options, err = routing.Normalize(options); err != nil {
+			ExitDefault(err)
+		}
+		body, err = utils.GenAmneziaWG(resStruct, options, routing, params)
+		if err != nil {
+			ExitDefault(err)
+		}
+	case E.HTTPProxy:
+		target, _ := cmd.Flags().GetString(asString(E.HTTPProxy) + "-target")
+		tag, _ := cmd.Flags().GetString(asString(E.HTTPProxy) + "-tag")
+		file, err := utils.GenHTTPProxy(resStruct, target, tag)
+		if err != nil {
+			ExitDefault(err)
+		}
+		if utils.HTTPProxyInTunnel(resStruct) {
+			fmt.Fprintln(os.Stderr, "Warn: the HTTP proxy "+resStruct.Config.Services.HTTPProxy+" has a private address and is only reachable through a connected WARP tunnel")
+		}
+		files = []utils.GeneratedFile{file}
+	case E.Netns:
+		name, _ := cmd.Flags().GetString(asString(E.Netns) + "-name")
+		iface, _ := cmd.Flags().GetString(asString(E.Netns) + "-interface")
+		body, err = utils.GenNetns(resStruct, options, name, iface)
 	}
 	if err != nil {
 		ExitDefault(err)
 	}
 
+	if files == nil {
+		files = []utils.GeneratedFile{{Body: body}}
+	}
+	if validate, _ := cmd.Flags().GetBool("validate"); validate {
+		for _, file := range files {
+			if err = utils.Validate(generator, file); err != nil {
+				ExitDefault(fmt.Errorf("%s: %w", asString(generator), err))
+			}
+		}
+	}
+
+	qr, _ := cmd.Flags().GetBool("qr")
+	qrFile, _ := cmd.Flags().GetString("qr-file")
+	if qr || qrFile != "" {
+		if generator == E.WgQuick {
+			// the WireGuard apps reject the wg-quick routing hooks, encode the app flavour instead
+			body, err = utils.GenWgApp(resStruct, options)
+			if err != nil {
+				ExitDefault(err)
+			}
+			files = []utils.GeneratedFile{{Body: body}}
+		}
+		writeQR(cmd, generator, files, resStruct.ID)
+	} else {
+		for _, file := range files {
+			writeOutput(cmd, outputType, generator, file, len(files) > 1, resStruct.ID)
+		}
+	}
+
+	// only a successful run becomes the new account defaults
+	if saveDefaults, _ := cmd.Flags().GetBool("save-defaults"); saveDefaults {
+		if err = saveGenerateDefaults(rawOptions); err != nil {
+			ExitDefault(err)
+		}
+	}
+}
+
+func writeQR(cmd *cobra.Command, generator E.GeneratorType, files []utils.GeneratedFile, id string) {
+	qr, _ := cmd.Flags().GetBool("qr")
+	qrFile, _ := cmd.Flags().GetString("qr-file")
+	invert, _ := cmd.Flags().GetBool("qr-invert")
+
+	if len(files) > 1 {
+		ExitDefault(errors.New("QR code output is not supported by " + asString(generator) + " generator"))
+	}
+	payloads := []string{strings.TrimSpace(string(files[0].Body))}
+	if generator == E.ShareLink {
+		payloads = strings.Fields(payloads[0])
+	}
+
+	for i, payload := range payloads {
+		if qr {
+			output, err := utils.RenderQRTerminal(payload, invert)
+			if err != nil {
+				ExitDefault(err)
+			}
+			fmt.Print(output)
+		}
+		if qrFile != "" {
+			var filepath = qrFile
+			if len(payloads) > 1 {
+				filepath = strings.TrimSuffix(qrFile, path.Ext(qrFile)) + "-" + strconv.Itoa(i+1) + path.Ext(qrFile)
+			}
+			askOutputOverwrite(filepath)
+			if err := utils.WriteQRFile(payload, filepath); err != nil {
+				ExitDefault(err)
+			}
+			fmt.Printf("Generate %s QR code '%s' (ID: %s) successfully\n", asString(generator), filepath, id)
+		}
+	}
+}
+
+func writeOutput(cmd *cobra.Command, outputType E.OutputFileType, generator E.GeneratorType, file utils.GeneratedFile, multiple bool, id string) {
+	var err error
+	var filepath string
 	switch outputType {
 	case E.Stdout:
-		_, err = fmt.Print(string(body))
-		if err != nil {
-			ExitDefault(err)
+		if multiple {
+			fmt.Printf("# %s\n", strings.TrimPrefix(file.Extension, "."))
 		}
-	case E.Default:
-		var filepath = getDefaultFilePath(generator)
-		askOutputOverwrite(filepath)
-		err = os.WriteFile(filepath, body, 0600)
+		_, err = fmt.Print(string(file.Body))
 		if err != nil {
 			ExitDefault(err)
 		}
-		fmt.Printf("Generate %s configuration file '%s' (ID: %s) successfully\n", asString(generator), filepath, resStruct.ID)
+		return
+	case E.Default:
+		filepath = getDefaultFilePath(generator)
 	case E.Custom:
-		filepath, _ := cmd.Flags().GetString("output-file")
-		askOutputOverwrite(filepath)
-		err = os.WriteFile(filepath, body, 0600)
-		if err != nil {
-			ExitDefault(err)
-		}
-		fmt.Printf("Generate %s configuration file '%s' (ID: %s) successfully\n", asString(generator), filepath, resStruct.ID)
+		filepath, _ = cmd.Flags().GetString("output-file")
+	}
+	if file.Extension != "" && (outputType == E.Default || multiple) {
+		filepath = strings.TrimSuffix(filepath, path.Ext(filepath)) + file.Extension
 	}
+
+	askOutputOverwrite(filepath)
+	err = os.WriteFile(filepath, file.Body, 0600)
+	if err != nil {
+		ExitDefault(err)
+	}
+	fmt.Printf("Generate %s configuration file '%s' (ID: %s) successfully\n", asString(generator), filepath, id)
 }
 
 func detectGeneratorType(cmd *cobra.Command) (E.GeneratorType, error) {
-	xray, _ := cmd.Flags().GetBool(asString(E.Xray))
-	sing, _ := cmd.Flags().GetBool(asString(E.SingBox))
-	wg, _ := cmd.Flags().GetBool(asString(E.WgQuick))
-	if !wg {
-		wg, _ = cmd.Flags().GetBool("wg")
+	var enabled []E.GeneratorType
+	for generator := E.GeneratorType(0); generator < E.None; generator++ {
+		value, _ := cmd.Flags().GetBool(asString(generator))
+		if alias, ok := generatorAliases[generator]; ok && !value {
+			value, _ = cmd.Flags().GetBool(alias)
+		}
+		if value {
+			enabled = append(enabled, generator)
+		}
 	}
 
-	var flagsEnabled = countTrue(xray, sing, wg)
-	if flagsEnabled != 1 {
-		if flagsEnabled == 0 {
+	if len(enabled) != 1 {
+		if len(enabled) == 0 {
 			return E.None, errors.New("generator not specified")
 		} else {
 			return E.None, errors.New("multiple generators not supported")
 		}
 	}
-
-	if xray {
-		return E.Xray, nil
-	} else if sing {
-		return E.SingBox, nil
-	} else if wg {
-		return E.WgQuick, nil
-	}
-	return E.None, nil
+	return enabled[0], nil
 }
 
 func detectOutputFileType(cmd *cobra.Command) (E.OutputFileType, error) {
@@ -194,18 +552,153 @@ func detectOutputFileType(cmd *cobra.Command) (E.OutputFileType, error) {
 	return E.Custom, nil
 }
 
-func detectEndpointType(cmd *cobra.Command) (E.EndpointType, error) {
-	endpointType, err := cmd.Flags().GetString(asString(E.Xray) + "-endpoint")
+func detectWgQuickRouting(cmd *cobra.Command) (routing utils.WgQuickRouting) {
+	routing.Mode, _ = cmd.Flags().GetString(asString(E.WgQuick) + "-routing")
+	routing.Table, _ = cmd.Flags().GetUint32(asString(E.WgQuick) + "-table")
+	routing.FwMark, _ = cmd.Flags().GetUint32(asString(E.WgQuick) + "-fwmark")
+	routing.Firewall, _ = cmd.Flags().GetString(asString(E.WgQuick) + "-firewall")
+	routing.KillSwitch, _ = cmd.Flags().GetBool("kill-switch")
+	return
+}
+
+func detectAmneziaWGParams(cmd *cobra.Command) (utils.AmneziaWGParams, error) {
+	preset, _ := cmd.Flags().GetString(asString(E.AmneziaWG) + "-preset")
+	params, ok := utils.AmneziaWGPresets[preset]
+	if !ok {
+		return params, errors.New("unsupported AmneziaWG preset " + preset)
+	}
+
+	intParams := map[string]*int{"jc": &params.Jc, "jmin": &params.Jmin, "jmax": &params.Jmax, "s1": &params.S1, "s2": &params.S2}
+	for param, value := range intParams {
+		if flag := asString(E.AmneziaWG) + "-" + param; cmd.Flags().Changed(flag) {
+			*value, _ = cmd.Flags().GetInt(flag)
+		}
+	}
+	headerParams := map[string]*uint32{"h1": &params.H1, "h2": &params.H2, "h3": &params.H3, "h4": &params.H4}
+	for param, value := range headerParams {
+		if flag := asString(E.AmneziaWG) + "-" + param; cmd.Flags().Changed(flag) {
+			*value, _ = cmd.Flags().GetUint32(flag)
+		}
+	}
+	return params, nil
+}
+
+// detectGenerateOptions overrides the account defaults with the options set on the command line,
+// the result is not normalized so that it can be stored as the new account defaults
+func detectGenerateOptions(cmd *cobra.Command, resStruct C.Response, generator E.GeneratorType) (C.GenerateOptions, error) {
+	options := utils.DefaultGenerateOptions()
+	if resStruct.GenerateDefaults != nil {
+		options = *resStruct.GenerateDefaults
+	}
+
+	if cmd.Flags().Changed("mtu") {
+		options.MTU, _ = cmd.Flags().GetInt("mtu")
+	}
+	if cmd.Flags().Changed("keepalive") {
+		options.Keepalive, _ = cmd.Flags().GetInt("keepalive")
+	}
+	if cmd.Flags().Changed("dns") {
+		options.DNS, _ = cmd.Flags().GetStringSlice("dns")
+		options.GatewayDNS = false
+		if len(options.DNS) == 1 && options.DNS[0] == "none" {
+			options.DNS = nil
+		}
+	}
+	if cmd.Flags().Changed("allowed-ips") {
+		options.AllowedIPs, _ = cmd.Flags().GetStringSlice("allowed-ips")
+	}
+	include, err := readPrefixFlags(cmd, "include")
+	if err != nil {
+		return options, err
+	}
+	if len(include) > 0 {
+		options.AllowedIPs = include
+	}
+	exclude, err := readPrefixFlags(cmd, "exclude")
 	if err != nil {
-		return E.Domain, err
+		return options, err
+	}
+	if len(exclude) == 1 && exclude[0] == "none" {
+		options.Exclude = nil
+	} else if len(exclude) > 0 {
+		options.Exclude = exclude
+	}
+	if cmd.Flags().Changed("family") {
+		options.Family, _ = cmd.Flags().GetString("family")
+	}
+	if generator == E.Xray && cmd.Flags().Changed(asString(E.Xray)+"-endpoint") {
+		options.Endpoint, _ = cmd.Flags().GetString(asString(E.Xray) + "-endpoint")
+	}
+	if cmd.Flags().Changed("endpoint") {
+		options.Endpoint, _ = cmd.Flags().GetString("endpoint")
+	}
+	if cmd.Flags().Changed("port") {
+		options.Port, _ = cmd.Flags().GetInt("port")
+	}
+	if cmd.Flags().Changed("fallback-resolver") {
+		options.FallbackResolver, _ = cmd.Flags().GetString("fallback-resolver")
 	}
-	switch endpointType {
-	case "domain":
-		return E.Domain, nil
-	case "ip_v4":
-		return E.IPv4, nil
-	case "ip_v6":
-		return E.IPv6, nil
+	if cmd.Flags().Changed("apply-policy") {
+		options.ApplyPolicy, _ = cmd.Flags().GetBool("apply-policy")
+	}
+	return options, nil
+}
+
+// applyPolicy turns the policy exclusions into AllowedIPs complements, xray and
+// sing-box route them directly instead, which needs a full config
+func applyPolicy(cmd *cobra.Command, resStruct C.Response, options C.GenerateOptions, generator E.GeneratorType) (C.GenerateOptions, error) {
+	if _, err := utils.PolicyExcludes(resStruct); err != nil {
+		return options, err
+	}
+	if resStruct.Policy.ExcludeOfficeIPs {
+		fmt.Fprintln(os.Stderr, "Warn: the policy excludes office IPs, which are not part of the account data")
+	}
+	switch generator {
+	case E.Xray, E.SingBox:
+		fullConfig, _ := cmd.Flags().GetBool("full")
+		if generator == E.SingBox {
+			fullProfile, _ := cmd.Flags().GetBool(asString(E.SingBox) + "-profile")
+			fullConfig = fullConfig || fullProfile
+		}
+		if !fullConfig {
+			fmt.Fprintln(os.Stderr, "Warn: policy exclusions of "+asString(generator)+" need a full config (--full) to be routed directly")
+		}
+		return options, nil
+	}
+	if hosts := utils.PolicyExcludeHosts(resStruct); len(hosts) > 0 {
+		fmt.Fprintln(os.Stderr, "Warn: domain exclusions of the policy cannot be applied to AllowedIPs and are skipped: "+strings.Join(hosts, ", "))
+	}
+	return utils.ApplyPolicyExclude(resStruct, options)
+}
+
+// readPrefixFlags collects the prefixes of the '<name>' flag and the '<name>-file' list files
+func readPrefixFlags(cmd *cobra.Command, name string) ([]string, error) {
+	prefixes, _ := cmd.Flags().GetStringSlice(name)
+	files, _ := cmd.Flags().GetStringArray(name + "-file")
+	for _, file := range files {
+		list, err := utils.ReadPrefixList(file)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, list...)
+	}
+	return prefixes, nil
+}
+
+func saveGenerateDefaults(options C.GenerateOptions) error {
+	// patch only generate_defaults, so that the API fields not modelled by C.Response are kept
+	var account map[string]json.RawMessage
+	if err := json.Unmarshal(utils.ReadConfig(configPath), &account); err != nil {
+		return err
+	}
+	defaults, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	account["generate_defaults"] = defaults
+	store, err := json.MarshalIndent(account, "", "    ")
+	if err != nil {
+		return err
 	}
-	return E.Domain, errors.New("unsupported endpoint type")
+	return os.WriteFile(configPath, store, 0600)
 }
@@ -70,6 +70,7 @@ func update(cmd *cobra.Command, args []string) {
 		response.Config.PrivateKey = resStruct.Config.PrivateKey
 	}
 	response.Token = resStruct.Token
+	response.GenerateDefaults = resStruct.GenerateDefaults
 	if updatedContent, err = json.MarshalIndent(response, "", "    "); err != nil {
 		fmt.Fprintln(os.Stderr, "Error:", err)
 		os.Exit(1)
@@ -6,11 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 
 	C "github.com/ArchiveNetwork/wgcf-cli/constant"
 	E "github.com/ArchiveNetwork/wgcf-cli/enum"
 	"github.com/ArchiveNetwork/wgcf-cli/utils"
+	"github.com/spf13/pflag"
 )
 
 func expectNoErr(err error, t *testing.T) {
@@ -33,10 +36,19 @@ func removeConfig(check checker) {
 }
 
 func beginGenerateTest(check checker) {
-	generateCmd.Flags().Set(asString(E.Xray), "false")
-	generateCmd.Flags().Set("wg", "false")
-	generateCmd.Flags().Set(asString(E.WgQuick), "false")
-	generateCmd.Flags().Set(asString(E.SingBox), "false")
+	// restore every generate flag, so that no value leaks from a previous test
+	generateCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if value, ok := flag.Value.(pflag.SliceValue); ok {
+			var defaults []string
+			if trimmed := strings.Trim(flag.DefValue, "[]"); trimmed != "" {
+				defaults = strings.Split(trimmed, ",")
+			}
+			value.Replace(defaults)
+		} else {
+			flag.Value.Set(flag.DefValue)
+		}
+		flag.Changed = false
+	})
 	createConfig(check)
 }
 func endGenerateTest(check checker, generator E.GeneratorType) {
@@ -97,6 +109,25 @@ func TestGenerateWg(t *testing.T) {
 		os.Remove(getDefaultFilePath(E.WgQuick))
 		rootCmd.SetArgs([]string{"generate", "--wg-quick"})
 		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--wg", "--mtu", "1420", "--keepalive", "0", "--family", "ipv4", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+
+		for _, mode := range utils.WgQuickRoutingModes {
+			rootCmd.SetArgs([]string{"generate", "--wg", "--wg-quick-routing", mode, "--wg-quick-table", "301", "--wg-quick-firewall", "nftables", "--output-file", "stdout"})
+			check(rootCmd.Execute())
+		}
+
+		for _, firewall := range utils.WgQuickFirewalls {
+			rootCmd.SetArgs([]string{"generate", "--wg", "--wg-quick-routing", "full", "--wg-quick-firewall", firewall, "--kill-switch", "--exclude", "private", "--output-file", "stdout"})
+			check(rootCmd.Execute())
+		}
+		generateCmd.Flags().Set("kill-switch", "false")
+
+		for _, endpoint := range []string{"domain", "ipv4", "ipv6", "auto", "engage.cloudflareclient.com:500"} {
+			rootCmd.SetArgs([]string{"generate", "--wg", "--endpoint", endpoint, "--port", "4500", "--output-file", "stdout"})
+			check(rootCmd.Execute())
+		}
 	})
 }
 
@@ -106,6 +137,12 @@ func TestGenerateSingBox(t *testing.T) {
 	runGenerateTest(check, E.WgQuick, func() {
 		rootCmd.SetArgs([]string{"generate", "--sing-box"})
 		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--sing-box", "--sing-box-version", "1.10", "--sing-box-profile", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--sing-box", "--sing-box-version", "1.10", "--exclude", "private", "--output-file", "stdout"})
+		check(rootCmd.Execute())
 	})
 }
 
@@ -115,5 +152,224 @@ func TestGenerateXray(t *testing.T) {
 	runGenerateTest(check, E.WgQuick, func() {
 		rootCmd.SetArgs([]string{"generate", "--xray"})
 		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--xray", "--full", "--xray-inbound", "http", "--xray-auth", "user:pass", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateClash(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.Clash, func() {
+		rootCmd.SetArgs([]string{"generate", "--clash"})
+		check(rootCmd.Execute())
+
+		os.Remove(getDefaultFilePath(E.Clash))
+		rootCmd.SetArgs([]string{"generate", "--mihomo", "--clash-profile", "--endpoint", "ipv6"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateWgSetconf(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.WgSetconf, func() {
+		rootCmd.SetArgs([]string{"generate", "--wg-setconf"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateSplitTunnel(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.WgSetconf, func() {
+		rootCmd.SetArgs([]string{"generate", "--wg-setconf", "--exclude", "private"})
+		check(rootCmd.Execute())
+
+		os.Remove(getDefaultFilePath(E.WgSetconf))
+		rootCmd.SetArgs([]string{"generate", "--wg-setconf", "--include", "1.0.0.0/8", "--exclude", "1.1.1.1"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateNetworkd(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.Networkd, func() {
+		rootCmd.SetArgs([]string{"generate", "--networkd", "--systemd-networkd-interface", "wg-warp"})
+		check(rootCmd.Execute())
+		os.Remove(strings.TrimSuffix(getDefaultFilePath(E.Networkd), ".netdev") + ".network")
+	})
+}
+
+func TestGenerateNetworkManager(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.NetworkManager, func() {
+		rootCmd.SetArgs([]string{"generate", "--nm"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateOpenWrt(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.OpenWrt, func() {
+		rootCmd.SetArgs([]string{"generate", "--openwrt"})
+		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--openwrt", "--openwrt-batch", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateSurge(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.Surge, func() {
+		rootCmd.SetArgs([]string{"generate", "--surge"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateLoon(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.Loon, func() {
+		rootCmd.SetArgs([]string{"generate", "--loon", "--endpoint", "ipv6"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateWireproxy(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.Wireproxy, func() {
+		rootCmd.SetArgs([]string{"generate", "--wireproxy", "--wireproxy-http", "127.0.0.1:40001", "--wireproxy-tunnel", "127.0.0.1:2222=example.com:22"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateRouterOS(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.RouterOS, func() {
+		rootCmd.SetArgs([]string{"generate", "--mikrotik"})
+		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--routeros", "--family", "ipv4", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateContainers(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.Gluetun, func() {
+		rootCmd.SetArgs([]string{"generate", "--gluetun"})
+		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--docker-compose", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--k8s", "--kubernetes-namespace", "default", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateShareLink(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.ShareLink, func() {
+		for _, dialect := range utils.ShareLinkDialects {
+			os.Remove(getDefaultFilePath(E.ShareLink))
+			rootCmd.SetArgs([]string{"generate", "--share-link", "--share-link-dialect", dialect})
+			check(rootCmd.Execute())
+		}
+	})
+}
+
+func TestGenerateQR(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+	const qrFile string = "wgcf.qr.svg"
+
+	runGenerateTest(check, E.WgQuick, func() {
+		rootCmd.SetArgs([]string{"generate", "--wg", "--qr"})
+		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--wg", "--qr-file", qrFile})
+		check(rootCmd.Execute())
+		os.Remove(qrFile)
+	})
+}
+
+func TestExportWireGuardZip(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+	const zipFile string = "wgcf.zip"
+
+	createConfig(check)
+	rootCmd.SetArgs([]string{"export", "--wireguard-zip", zipFile, "--all-endpoints"})
+	check(rootCmd.Execute())
+	os.Remove(zipFile)
+	removeConfig(check)
+}
+
+func TestGenerateAmneziaWG(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.AmneziaWG, func() {
+		rootCmd.SetArgs([]string{"generate", "--awg", "--amneziawg-preset", "medium", "--amneziawg-jc", "6"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateHTTPProxy(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.HTTPProxy, func() {
+		for _, target := range utils.HTTPProxyTargets {
+			rootCmd.SetArgs([]string{"generate", "--http-proxy", "--http-proxy-target", target, "--output-file", "stdout"})
+			check(rootCmd.Execute())
+		}
+	})
+}
+
+func TestGenerateNetns(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.Netns, func() {
+		rootCmd.SetArgs([]string{"generate", "--netns"})
+		check(rootCmd.Execute())
+
+		rootCmd.SetArgs([]string{"generate", "--netns", "--netns-name", "warp-apps", "--netns-interface", "warp0", "--dns", "none", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+	})
+}
+
+func TestGenerateSaveDefaults(t *testing.T) {
+	check := func(err error) { expectNoErr(err, t) }
+
+	runGenerateTest(check, E.WgQuick, func() {
+		rootCmd.SetArgs([]string{"generate", "--wg", "--family", "ipv4", "--exclude", "private", "--save-defaults", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+
+		var account struct {
+			GenerateDefaults C.GenerateOptions `json:"generate_defaults"`
+		}
+		check(json.Unmarshal(utils.ReadConfig(ConfigPathDefault), &account))
+		if !slices.Contains(account.GenerateDefaults.AllowedIPs, "::/0") || !slices.Equal(account.GenerateDefaults.Exclude, []string{"private"}) {
+			t.Errorf("Expected the raw options to be saved, but got: %+v", account.GenerateDefaults)
+		}
+
+		generateCmd.Flags().Set("family", "both")
+		generateCmd.Flags().Lookup("exclude").Value.(interface{ Replace([]string) error }).Replace(nil)
+		rootCmd.SetArgs([]string{"generate", "--wg", "--exclude", "none", "--save-defaults", "--output-file", "stdout"})
+		check(rootCmd.Execute())
+		account.GenerateDefaults = C.GenerateOptions{}
+		check(json.Unmarshal(utils.ReadConfig(ConfigPathDefault), &account))
+		if len(account.GenerateDefaults.Exclude) != 0 {
+			t.Errorf("Expected --exclude none to clear the saved exclusions, but got: %v", account.GenerateDefaults.Exclude)
+		}
 	})
 }
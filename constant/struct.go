@@ -1,15 +1,18 @@
 package constant
 
+type XrayPeer struct {
+	PublicKey  string   `json:"publicKey"`
+	AllowedIPs []string `json:"allowedIPs"`
+	Endpoint   string   `json:"endpoint"`
+	KeepAlive  int      `json:"keepAlive,omitempty"`
+}
+
 type XraySettings struct {
-	SecretKey string   `json:"secretKey"`
-	Address   []string `json:"address"`
-	Peers     []struct {
-		PublicKey  string   `json:"publicKey"`
-		AllowedIPs []string `json:"allowedIPs"`
-		Endpoint   string   `json:"endpoint"`
-	} `json:"peers"`
-	Reserved []int `json:"reserved"`
-	MTU      int   `json:"mtu"`
+	SecretKey string     `json:"secretKey"`
+	Address   []string   `json:"address"`
+	Peers     []XrayPeer `json:"peers"`
+	Reserved  []int      `json:"reserved"`
+	MTU       int        `json:"mtu"`
 }
 
 type Xray struct {
@@ -18,16 +21,186 @@ type Xray struct {
 	Tag      string       `json:"tag"`
 }
 
+type XrayAccount struct {
+	User string `json:"user"`
+	Pass string `json:"pass"`
+}
+
+type XrayInboundSettings struct {
+	Auth     string        `json:"auth,omitempty"`
+	Accounts []XrayAccount `json:"accounts,omitempty"`
+	UDP      bool          `json:"udp,omitempty"`
+}
+
+type XrayInbound struct {
+	Tag      string              `json:"tag"`
+	Listen   string              `json:"listen"`
+	Port     uint16              `json:"port"`
+	Protocol string              `json:"protocol"`
+	Settings XrayInboundSettings `json:"settings"`
+	Sniffing struct {
+		Enabled      bool     `json:"enabled"`
+		DestOverride []string `json:"destOverride"`
+	} `json:"sniffing"`
+}
+
+type XrayOutbound struct {
+	Protocol string `json:"protocol"`
+	Tag      string `json:"tag"`
+}
+
+type XrayHTTPServer struct {
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+}
+
+type XrayHTTPOutbound struct {
+	Protocol string `json:"protocol"`
+	Settings struct {
+		Servers []XrayHTTPServer `json:"servers"`
+	} `json:"settings"`
+	Tag string `json:"tag"`
+}
+
+type XrayDNSServer struct {
+	Address string   `json:"address"`
+	Domains []string `json:"domains,omitempty"`
+}
+
+type XrayDNS struct {
+	Servers []any `json:"servers"`
+}
+
+type XrayRoutingRule struct {
+	Type        string   `json:"type"`
+	IP          []string `json:"ip,omitempty"`
+	Domain      []string `json:"domain,omitempty"`
+	OutboundTag string   `json:"outboundTag"`
+}
+
+type XrayRouting struct {
+	DomainStrategy string            `json:"domainStrategy"`
+	Rules          []XrayRoutingRule `json:"rules"`
+}
+
+type XrayConfig struct {
+	Log *struct {
+		LogLevel string `json:"loglevel"`
+	} `json:"log,omitempty"`
+	DNS       *XrayDNS      `json:"dns,omitempty"`
+	Inbounds  []XrayInbound `json:"inbounds,omitempty"`
+	Outbounds []any         `json:"outbounds"`
+	Routing   *XrayRouting  `json:"routing,omitempty"`
+}
+
 type Sing struct {
-	Type          string   `json:"type"`
-	Tag           string   `json:"tag"`
-	Server        string   `json:"server"`
-	ServerPort    int      `json:"server_port"`
-	LocalAddress  []string `json:"local_address"`
-	PrivateKey    string   `json:"private_key"`
-	PeerPublicKey string   `json:"peer_public_key"`
-	Reserved      string   `json:"reserved"`
-	MTU           int      `json:"mtu"`
+	Type          string     `json:"type"`
+	Tag           string     `json:"tag"`
+	Server        string     `json:"server,omitempty"`
+	ServerPort    int        `json:"server_port,omitempty"`
+	LocalAddress  []string   `json:"local_address"`
+	PrivateKey    string     `json:"private_key"`
+	PeerPublicKey string     `json:"peer_public_key,omitempty"`
+	Peers         []SingPeer `json:"peers,omitempty"`
+	Reserved      []int      `json:"reserved,omitempty"`
+	MTU           int        `json:"mtu"`
+}
+
+// SingPeer is a peer of the legacy wireguard outbound, which has no keepalive option
+type SingPeer struct {
+	Server     string   `json:"server"`
+	ServerPort int      `json:"server_port"`
+	PublicKey  string   `json:"public_key"`
+	AllowedIPs []string `json:"allowed_ips"`
+	Reserved   []int    `json:"reserved"`
+}
+
+type SingEndpointPeer struct {
+	Address                     string   `json:"address"`
+	Port                        int      `json:"port"`
+	PublicKey                   string   `json:"public_key"`
+	AllowedIPs                  []string `json:"allowed_ips"`
+	PersistentKeepaliveInterval int      `json:"persistent_keepalive_interval,omitempty"`
+	Reserved                    []int    `json:"reserved"`
+}
+
+type SingEndpoint struct {
+	Type       string             `json:"type"`
+	Tag        string             `json:"tag"`
+	MTU        int                `json:"mtu"`
+	Address    []string           `json:"address"`
+	PrivateKey string             `json:"private_key"`
+	Peers      []SingEndpointPeer `json:"peers"`
+}
+
+type SingInbound struct {
+	Type         string   `json:"type"`
+	Tag          string   `json:"tag"`
+	Listen       string   `json:"listen,omitempty"`
+	ListenPort   int      `json:"listen_port,omitempty"`
+	Address      []string `json:"address,omitempty"`
+	Inet4Address string   `json:"inet4_address,omitempty"`
+	Inet6Address string   `json:"inet6_address,omitempty"`
+	AutoRoute    bool     `json:"auto_route,omitempty"`
+	StrictRoute  bool     `json:"strict_route,omitempty"`
+	Sniff        bool     `json:"sniff,omitempty"`
+}
+
+type SingOutbound struct {
+	Type string `json:"type"`
+	Tag  string `json:"tag"`
+}
+
+type SingHTTPOutbound struct {
+	Type       string `json:"type"`
+	Tag        string `json:"tag"`
+	Server     string `json:"server"`
+	ServerPort int    `json:"server_port"`
+}
+
+type SingDNSServer struct {
+	Tag             string `json:"tag"`
+	Address         string `json:"address"`
+	AddressResolver string `json:"address_resolver,omitempty"`
+	Detour          string `json:"detour,omitempty"`
+}
+
+type SingDNSRule struct {
+	Outbound     string   `json:"outbound,omitempty"`
+	DomainSuffix []string `json:"domain_suffix,omitempty"`
+	Server       string   `json:"server"`
+}
+
+type SingDNS struct {
+	Servers []SingDNSServer `json:"servers"`
+	Rules   []SingDNSRule   `json:"rules,omitempty"`
+	Final   string          `json:"final,omitempty"`
+}
+
+type SingRouteRule struct {
+	Action       string   `json:"action,omitempty"`
+	Protocol     string   `json:"protocol,omitempty"`
+	IPIsPrivate  bool     `json:"ip_is_private,omitempty"`
+	IPCIDR       []string `json:"ip_cidr,omitempty"`
+	DomainSuffix []string `json:"domain_suffix,omitempty"`
+	Outbound     string   `json:"outbound,omitempty"`
+}
+
+type SingRoute struct {
+	Rules               []SingRouteRule `json:"rules"`
+	Final               string          `json:"final"`
+	AutoDetectInterface bool            `json:"auto_detect_interface"`
+}
+
+type SingProfile struct {
+	Log struct {
+		Level string `json:"level"`
+	} `json:"log"`
+	DNS       SingDNS        `json:"dns"`
+	Inbounds  []SingInbound  `json:"inbounds"`
+	Endpoints []SingEndpoint `json:"endpoints,omitempty"`
+	Outbounds []any          `json:"outbounds"`
+	Route     SingRoute      `json:"route"`
 }
 
 type SimpleOutput struct {
@@ -46,6 +219,20 @@ type SimpleOutput struct {
 	} `json:"addresses"`
 }
 
+type GenerateOptions struct {
+	MTU              int      `json:"mtu"`
+	Keepalive        int      `json:"keepalive"`
+	DNS              []string `json:"dns"`
+	AllowedIPs       []string `json:"allowed_ips"`
+	Exclude          []string `json:"exclude,omitempty"`
+	Family           string   `json:"family"`
+	ApplyPolicy      bool     `json:"apply_policy,omitempty"`
+	GatewayDNS       bool     `json:"gateway_dns,omitempty"`
+	Endpoint         string   `json:"endpoint"`
+	Port             int      `json:"port,omitempty"`
+	FallbackResolver string   `json:"fallback_resolver"`
+}
+
 type ResponsePeer struct {
 	PublicKey string `json:"public_key"`
 	Endpoint  struct {
@@ -88,7 +275,8 @@ type Response struct {
 			Suffix string `json:"suffix"`
 		} `json:"fallback_domains"`
 		Exclude []struct {
-			Address     string `json:"address"`
+			Address     string `json:"address,omitempty"`
+			Host        string `json:"host,omitempty"`
 			Description string `json:"description,omitempty"`
 		} `json:"exclude"`
 		GatewayUniqueID  string `json:"gateway_unique_id"`
@@ -127,11 +315,77 @@ type Response struct {
 			HTTPProxy string `json:"http_proxy"`
 		} `json:"services"`
 	} `json:"config"`
-	Model         string `json:"model,omitempty"`
-	OverrideCodes *struct {
+	Model            string           `json:"model,omitempty"`
+	GenerateDefaults *GenerateOptions `json:"generate_defaults,omitempty"`
+	OverrideCodes    *struct {
 		DisableForTime struct {
 			Seconds int    `json:"seconds"`
 			Secret  string `json:"secret"`
 		} `json:"disable_for_time"`
 	} `json:"override_codes,omitempty"`
 }
+
+type ClashProxy struct {
+	Name                string   `yaml:"name"`
+	Type                string   `yaml:"type"`
+	Server              string   `yaml:"server"`
+	Port                int      `yaml:"port"`
+	IP                  string   `yaml:"ip,omitempty"`
+	IPv6                string   `yaml:"ipv6,omitempty"`
+	PrivateKey          string   `yaml:"private-key"`
+	PublicKey           string   `yaml:"public-key"`
+	AllowedIPs          []string `yaml:"allowed-ips,flow"`
+	Reserved            []int    `yaml:"reserved,flow"`
+	MTU                 int      `yaml:"mtu"`
+	PersistentKeepalive int      `yaml:"persistent-keepalive,omitempty"`
+	UDP                 bool     `yaml:"udp"`
+	RemoteDNSResolve    bool     `yaml:"remote-dns-resolve,omitempty"`
+	DNS                 []string `yaml:"dns,flow,omitempty"`
+}
+
+type ClashHTTPProxy struct {
+	Name   string `yaml:"name"`
+	Type   string `yaml:"type"`
+	Server string `yaml:"server"`
+	Port   int    `yaml:"port"`
+}
+
+type ClashProxyGroup struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	Proxies []string `yaml:"proxies,flow"`
+}
+
+type Clash struct {
+	MixedPort   int               `yaml:"mixed-port,omitempty"`
+	Mode        string            `yaml:"mode,omitempty"`
+	LogLevel    string            `yaml:"log-level,omitempty"`
+	IPv6        bool              `yaml:"ipv6,omitempty"`
+	Proxies     []ClashProxy      `yaml:"proxies"`
+	ProxyGroups []ClashProxyGroup `yaml:"proxy-groups,omitempty"`
+	Rules       []string          `yaml:"rules,omitempty"`
+}
+
+type ComposeService struct {
+	Image         string   `yaml:"image"`
+	ContainerName string   `yaml:"container_name"`
+	CapAdd        []string `yaml:"cap_add"`
+	Devices       []string `yaml:"devices"`
+	Environment   []string `yaml:"environment"`
+	Restart       string   `yaml:"restart"`
+}
+
+type Compose struct {
+	Services map[string]ComposeService `yaml:"services"`
+}
+
+type KubernetesSecret struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace,omitempty"`
+	} `yaml:"metadata"`
+	Type string            `yaml:"type"`
+	Data map[string]string `yaml:"data"`
+}
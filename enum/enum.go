@@ -14,6 +14,22 @@ const (
 	Xray GeneratorType = iota
 	SingBox
 	WgQuick
+	Clash
+	WgSetconf
+	Networkd
+	NetworkManager
+	OpenWrt
+	Surge
+	Loon
+	Wireproxy
+	RouterOS
+	Gluetun
+	Compose
+	Kubernetes
+	ShareLink
+	AmneziaWG
+	HTTPProxy
+	Netns
 	None
 )
 
@@ -23,6 +39,8 @@ const (
 	Domain EndpointType = iota
 	IPv4
 	IPv6
+	Auto
+	CustomEndpoint
 )
 
 func (t GeneratorType) String() string {
@@ -33,6 +51,38 @@ func (t GeneratorType) String() string {
 		return "sing-box"
 	case WgQuick:
 		return "wg-quick"
+	case Clash:
+		return "clash"
+	case WgSetconf:
+		return "wg-setconf"
+	case Networkd:
+		return "systemd-networkd"
+	case NetworkManager:
+		return "networkmanager"
+	case OpenWrt:
+		return "openwrt"
+	case Surge:
+		return "surge"
+	case Loon:
+		return "loon"
+	case Wireproxy:
+		return "wireproxy"
+	case RouterOS:
+		return "routeros"
+	case Gluetun:
+		return "gluetun"
+	case Compose:
+		return "docker-compose"
+	case Kubernetes:
+		return "kubernetes"
+	case ShareLink:
+		return "share-link"
+	case AmneziaWG:
+		return "amneziawg"
+	case HTTPProxy:
+		return "http-proxy"
+	case Netns:
+		return "netns"
 	}
 	return "unknown"
 }
@@ -42,9 +92,13 @@ func (ep EndpointType) String() string {
 	case Domain:
 		return "domain"
 	case IPv4:
-		return "ip_v4"
+		return "ipv4"
 	case IPv6:
-		return "ip_v6"
+		return "ipv6"
+	case Auto:
+		return "auto"
+	case CustomEndpoint:
+		return "custom"
 	}
 	return "unknown"
 }
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+	E "github.com/ArchiveNetwork/wgcf-cli/enum"
+)
+
+// EndpointTypes lists the endpoint selections besides a custom 'host:port'
+var EndpointTypes = []E.EndpointType{E.Domain, E.IPv4, E.IPv6, E.Auto}
+
+const defaultEndpointPort uint16 = 2408
+
+// Endpoint is the WARP peer endpoint of an account
+type Endpoint struct {
+	Host  string
+	V4    netip.Addr
+	V6    netip.Addr
+	Ports []uint16
+}
+
+// ParseEndpoint reads the peer endpoint of the account, the addresses may carry a port or not
+func ParseEndpoint(resStruct C.Response) (endpoint Endpoint, err error) {
+	if len(resStruct.Config.Peers) == 0 {
+		return endpoint, errors.New("account has no peer")
+	}
+	peer := resStruct.Config.Peers[0].Endpoint
+	for _, port := range peer.Ports {
+		if port != 0 && port <= 65535 {
+			endpoint.Ports = append(endpoint.Ports, uint16(port))
+		}
+	}
+
+	endpoint.Host = peer.Host
+	if host, port, err := net.SplitHostPort(peer.Host); err == nil {
+		endpoint.Host = host
+		if parsed, err := strconv.ParseUint(port, 10, 16); err == nil && parsed != 0 && !containsPort(endpoint.Ports, uint16(parsed)) {
+			endpoint.Ports = append([]uint16{uint16(parsed)}, endpoint.Ports...)
+		}
+	}
+	if endpoint.V4, err = parseEndpointAddr(peer.V4); err != nil {
+		return
+	}
+	if endpoint.V6, err = parseEndpointAddr(peer.V6); err != nil {
+		return
+	}
+	if len(endpoint.Ports) == 0 {
+		endpoint.Ports = []uint16{defaultEndpointPort}
+	}
+	return
+}
+
+// parseEndpointAddr parses an address with or without a port, an empty address is left invalid
+func parseEndpointAddr(value string) (netip.Addr, error) {
+	if value == "" {
+		return netip.Addr{}, nil
+	}
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap(), nil
+	}
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	return addr.Unmap(), err
+}
+
+func containsPort(ports []uint16, port uint16) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseEndpointType parses an --endpoint value, anything in 'host:port' format is a custom endpoint
+func ParseEndpointType(value string) (E.EndpointType, error) {
+	switch value {
+	case "domain":
+		return E.Domain, nil
+	case "ipv4", "ip_v4":
+		return E.IPv4, nil
+	case "ipv6", "ip_v6":
+		return E.IPv6, nil
+	case "auto", "":
+		return E.Auto, nil
+	}
+	if host, port, err := net.SplitHostPort(value); err == nil && host != "" {
+		if parsed, err := strconv.ParseUint(port, 10, 16); err == nil && parsed != 0 {
+			return E.CustomEndpoint, nil
+		}
+		return E.Auto, errors.New("port of endpoint " + value + " must be between 1 and 65535")
+	}
+	return E.Auto, errors.New("unsupported endpoint " + value)
+}
+
+// Select returns the host and port to connect to. Auto picks the address of
+// an enabled family, preferring IPv4, and falls back to the domain
+func (endpoint Endpoint) Select(options C.GenerateOptions) (host string, port int, err error) {
+	endpointType, err := ParseEndpointType(options.Endpoint)
+	if err != nil {
+		return
+	}
+	port = int(endpoint.Ports[0])
+	if endpointType == E.Auto {
+		switch {
+		case hasIPv4(options) && endpoint.V4.IsValid():
+			endpointType = E.IPv4
+		case hasIPv6(options) && endpoint.V6.IsValid():
+			endpointType = E.IPv6
+		default:
+			endpointType = E.Domain
+		}
+	}
+
+	switch endpointType {
+	case E.Domain:
+		host = endpoint.Host
+	case E.IPv4:
+		if endpoint.V4.IsValid() {
+			host = endpoint.V4.String()
+		}
+	case E.IPv6:
+		if endpoint.V6.IsValid() {
+			host = endpoint.V6.String()
+		}
+	case E.CustomEndpoint:
+		var customPort string
+		host, customPort, _ = net.SplitHostPort(options.Endpoint)
+		port, _ = strconv.Atoi(customPort)
+	}
+	if host == "" {
+		return "", 0, errors.New("account has no " + endpointType.String() + " endpoint")
+	}
+	if options.Port != 0 {
+		port = options.Port
+	}
+	return
+}
+
+// SelectEndpoint returns the host and port of the account endpoint selected by options
+func SelectEndpoint(resStruct C.Response, options C.GenerateOptions) (host string, port int, err error) {
+	endpoint, err := ParseEndpoint(resStruct)
+	if err != nil {
+		return
+	}
+	return endpoint.Select(options)
+}
+
+// isIPEndpoint reports whether the endpoint host is an IP address
+func isIPEndpoint(host string) bool {
+	_, err := netip.ParseAddr(host)
+	return err == nil
+}
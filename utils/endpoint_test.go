@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+	E "github.com/ArchiveNetwork/wgcf-cli/enum"
+)
+
+func TestParseEndpointType(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    E.EndpointType
+		wantErr bool
+	}{
+		{"domain", E.Domain, false},
+		{"ip_v4", E.IPv4, false},
+		{"ipv6", E.IPv6, false},
+		{"", E.Auto, false},
+		{"1.2.3.4:443", E.CustomEndpoint, false},
+		{"[2606:4700::1]:65535", E.CustomEndpoint, false},
+		{"engage.cloudflareclient.com:500", E.CustomEndpoint, false},
+		{"1.2.3.4:0", E.Auto, true},
+		{"1.2.3.4:65536", E.Auto, true},
+		{":500", E.Auto, true},
+		{"1.2.3.4", E.Auto, true},
+	}
+	for _, test := range tests {
+		got, err := ParseEndpointType(test.value)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ParseEndpointType(%q) = %v, %v, expected %v with error %t", test.value, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestSelectEndpoint(t *testing.T) {
+	resStruct := testResponse(t)
+	tests := []struct {
+		endpoint string
+		family   string
+		port     int
+		want     string
+	}{
+		{"domain", "both", 0, "engage.cloudflareclient.com:2408"},
+		{"auto", "both", 0, "162.159.192.1:2408"},
+		{"auto", "ipv6", 500, "[2606:4700:d0::a29f:c001]:500"},
+		{"ipv6", "both", 0, "[2606:4700:d0::a29f:c001]:2408"},
+		{"1.2.3.4:443", "both", 0, "1.2.3.4:443"},
+	}
+	for _, test := range tests {
+		options := testOptions(t, func(options *C.GenerateOptions) {
+			options.Endpoint, options.Family, options.Port = test.endpoint, test.family, test.port
+		})
+		got, err := joinAddress(resStruct, options)
+		if err != nil || got != test.want {
+			t.Errorf("endpoint %q with family %s = %q, %v, expected %q", test.endpoint, test.family, got, err, test.want)
+		}
+	}
+
+	resStruct.Config.Peers[0].Endpoint.V6 = ""
+	options := testOptions(t, func(options *C.GenerateOptions) { options.Endpoint = "ipv6" })
+	if _, err := GenClash(resStruct, options, "WARP", false); err == nil {
+		t.Error("Expected an error for a missing ipv6 endpoint")
+	}
+}
@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+	E "github.com/ArchiveNetwork/wgcf-cli/enum"
+)
+
+// TunnelNameMaxLength is the longest tunnel name accepted by the WireGuard apps
+const TunnelNameMaxLength int = 15
+
+var tunnelNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_=+.-]`)
+
+var tunnelSuffixes = map[E.EndpointType]string{
+	E.Domain: "-dn",
+	E.IPv4:   "-v4",
+	E.IPv6:   "-v6",
+}
+
+// SanitizeTunnelName replaces the characters the WireGuard apps reject and
+// shortens name so that it still fits when suffix is appended
+func SanitizeTunnelName(name string, suffix string) string {
+	name = tunnelNameInvalid.ReplaceAllString(name, "_")
+	suffix = tunnelNameInvalid.ReplaceAllString(suffix, "_")
+	if len(suffix) > TunnelNameMaxLength {
+		suffix = suffix[:TunnelNameMaxLength]
+	}
+	if len(name)+len(suffix) > TunnelNameMaxLength {
+		name = name[:TunnelNameMaxLength-len(suffix)]
+	}
+	if name+suffix == "" {
+		return "warp"
+	}
+	return name + suffix
+}
+
+// GenWgApp generates a tunnel config accepted by the WireGuard apps, without wg-quick hooks
+func GenWgApp(resStruct C.Response, options C.GenerateOptions) (body []byte, err error) {
+	endpoint, err := joinAddress(resStruct, options)
+	if err != nil {
+		return
+	}
+	var dns string
+	if len(options.DNS) > 0 {
+		dns = "DNS = " + strings.Join(options.DNS, ", ") + "\n"
+	}
+
+	inStr := fmt.Sprint(`[Interface]
+PrivateKey = ` + resStruct.Config.PrivateKey + `
+Address = ` + strings.Join(interfaceAddresses(resStruct, options), ", ") + `
+` + dns + `MTU = ` + strconv.Itoa(options.MTU) + `
+
+[Peer]
+PublicKey = ` + resStruct.Config.Peers[0].PublicKey + `
+` + policyComments(resStruct, options) + `AllowedIPs = ` + strings.Join(options.AllowedIPs, ", ") + `
+Endpoint = ` + endpoint + `
+` + keepaliveLine("PersistentKeepalive", options))
+	body = []byte(inStr)
+	return
+}
+
+// Tunnel is one account exported into a WireGuard app bundle
+type Tunnel struct {
+	Name     string
+	Response C.Response
+	Options  C.GenerateOptions
+}
+
+// GenWireGuardZip bundles a tunnel config per account, and per endpoint type when allEndpoints is set,
+// skipping the types of disabled families and the endpoints an account does not have
+func GenWireGuardZip(tunnels []Tunnel, allEndpoints bool) (body []byte, err error) {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	used := map[string]bool{}
+
+	endpointTypes := []E.EndpointType{E.Auto}
+	if allEndpoints {
+		endpointTypes = []E.EndpointType{E.Domain, E.IPv4, E.IPv6}
+	}
+	for _, tunnel := range tunnels {
+		for _, endpointType := range endpointTypes {
+			options := tunnel.Options
+			var suffix string
+			if allEndpoints {
+				if endpointType == E.IPv4 && !hasIPv4(options) || endpointType == E.IPv6 && !hasIPv6(options) {
+					continue
+				}
+				options.Endpoint = endpointType.String()
+				suffix = tunnelSuffixes[endpointType]
+			}
+			if _, _, err = SelectEndpoint(tunnel.Response, options); err != nil {
+				if allEndpoints {
+					continue
+				}
+				return nil, err
+			}
+			name := SanitizeTunnelName(tunnel.Name, suffix)
+			for i := 2; used[name]; i++ {
+				name = SanitizeTunnelName(tunnel.Name, strconv.Itoa(i)+suffix)
+			}
+			used[name] = true
+
+			config, err := GenWgApp(tunnel.Response, options)
+			if err != nil {
+				return nil, err
+			}
+			writer, err := archive.CreateHeader(&zip.FileHeader{
+				Name:     name + ".conf",
+				Method:   zip.Deflate,
+				Modified: time.Now(),
+			})
+			if err != nil {
+				return nil, err
+			}
+			if _, err = writer.Write(config); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(used) == 0 {
+		return nil, errors.New("no account has an endpoint to export")
+	}
+	if err = archive.Close(); err != nil {
+		return nil, err
+	}
+	body = buffer.Bytes()
+	return
+}
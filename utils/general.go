@@ -2,6 +2,7 @@ package utils
 
 import (
 	crand "crypto/rand"
+	"crypto/sha1"
 	"encoding/base64"
 	"encoding/hex"
 	"math/rand"
@@ -66,6 +67,14 @@ func GenerateKey() (string, string) {
 	return base64.StdEncoding.EncodeToString(priv[:]), base64.StdEncoding.EncodeToString(pub[:])
 }
 
+// NameUUID derives a stable RFC 4122 version 5 style UUID from name
+func NameUUID(name string) string {
+	sum := sha1.Sum([]byte(name))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	hexString := hex.EncodeToString(sum[:16])
+	return hexString[0:8] + "-" + hexString[8:12] + "-" + hexString[12:16] + "-" + hexString[16:20] + "-" + hexString[20:32]
+}
 
 func Ternary[V any](condition bool, on_true V, on_false V) V {
 	if condition {
@@ -1,49 +1,92 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"net"
 	"strconv"
 	"strings"
 
 	C "github.com/ArchiveNetwork/wgcf-cli/constant"
-	E "github.com/ArchiveNetwork/wgcf-cli/enum"
+	"gopkg.in/yaml.v3"
 )
 
-func constructAddress(resStruct C.Response, endpoint_type E.EndpointType) string {
-	var peer *C.ResponsePeer = &resStruct.Config.Peers[0]
-	switch endpoint_type {
-	case E.Domain:
-		return peer.Endpoint.Host
-	case E.IPv4:
-		return peer.Endpoint.V4 + ":" + strconv.Itoa(int(peer.Endpoint.Ports[0]))
-	case E.IPv6:
-		return peer.Endpoint.V6 + ":" + strconv.Itoa(int(peer.Endpoint.Ports[0]))
+// GeneratedFile is one of the files produced by a generator which outputs several files
+type GeneratedFile struct {
+	Extension string
+	Body      []byte
+}
+
+const (
+	routeTable   int = 300
+	firewallMark int = 32975
+)
+
+var privateCIDRs = []string{
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// joinAddress returns the endpoint selected by options in 'host:port' format
+func joinAddress(resStruct C.Response, options C.GenerateOptions) (string, error) {
+	host, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port)), nil
+}
+
+func marshalYaml(value any) (body []byte, err error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(value); err != nil {
+		return
+	}
+	if err = encoder.Close(); err != nil {
+		return
 	}
-	return ""
+	body = buffer.Bytes()
+	return
 }
 
-func GenXray(resStruct C.Response, tag string, configModule string, indentSize uint8, endpointType E.EndpointType) (body []byte, err error) {
-	configBodyJson := C.Xray{
+func xrayOutbound(resStruct C.Response, options C.GenerateOptions, tag string) (C.Xray, error) {
+	endpoint, err := joinAddress(resStruct, options)
+	if err != nil {
+		return C.Xray{}, err
+	}
+	return C.Xray{
 		Protocol: "wireguard",
 		Settings: C.XraySettings{
 			SecretKey: resStruct.Config.PrivateKey,
-			Address:   []string{resStruct.Config.Interface.Addresses.V4 + "/32", resStruct.Config.Interface.Addresses.V6 + "/128"},
-			Peers: []struct {
-				PublicKey  string   `json:"publicKey"`
-				AllowedIPs []string `json:"allowedIPs"`
-				Endpoint   string   `json:"endpoint"`
-			}{
+			Address:   interfaceAddresses(resStruct, options),
+			Peers: []C.XrayPeer{
 				{
 					PublicKey:  resStruct.Config.Peers[0].PublicKey,
-					AllowedIPs: []string{"0.0.0.0/0", "::/0"},
-					Endpoint:   constructAddress(resStruct, endpointType),
+					AllowedIPs: options.AllowedIPs,
+					Endpoint:   endpoint,
+					KeepAlive:  options.Keepalive,
 				},
 			},
 			Reserved: resStruct.Config.ReservedDec,
-			MTU:      1280,
+			MTU:      options.MTU,
 		},
 		Tag: tag,
+	}, nil
+}
+
+func GenXray(resStruct C.Response, options C.GenerateOptions, tag string, configModule string, indentSize uint8) (body []byte, err error) {
+	configBodyJson, err := xrayOutbound(resStruct, options, tag)
+	if err != nil {
+		return
 	}
 
 	indent := strings.Repeat(" ", int(indentSize))
@@ -56,59 +99,48 @@ func GenXray(resStruct C.Response, tag string, configModule string, indentSize u
 	return
 }
 
-func GenSing(resStruct C.Response) (body []byte, err error) {
-	inStruct := C.Sing{
-		Type:          "wireguard",
-		Tag:           "wireguard-out",
-		Server:        resStruct.Config.Peers[0].Endpoint.Host,
-		ServerPort:    2408,
-		LocalAddress:  []string{resStruct.Config.Interface.Addresses.V4 + "/32", resStruct.Config.Interface.Addresses.V6 + "/128"},
-		PrivateKey:    resStruct.Config.PrivateKey,
-		PeerPublicKey: "bmXOC+F1FxEMF9dyiK2H5/1SUtzH0JuVo51h2wPfgyo=",
-		Reserved:      resStruct.Config.ClientID,
-		MTU:           1280,
-	}
-
-	body, err = json.MarshalIndent(inStruct, "", "    ")
-	return
+func GenWgQuick(resStruct C.Response, options C.GenerateOptions, routing WgQuickRouting) (body []byte, err error) {
+	return genWgQuick(resStruct, options, routing, "")
 }
 
-func GenWgQuick(resStruct C.Response) (body []byte, err error) {
+// genWgQuick generates a wg-quick config, interfaceExtra is appended to the [Interface] base fields
+func genWgQuick(resStruct C.Response, options C.GenerateOptions, routing WgQuickRouting, interfaceExtra string) (body []byte, err error) {
+	host, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return
+	}
+	var dns string
+	if len(options.DNS) > 0 {
+		dns = "DNS = " + strings.Join(options.DNS, ", ") + "\n"
+	}
+
 	inStr := fmt.Sprint(`
 [Interface]
 PrivateKey = ` + resStruct.Config.PrivateKey + `
-Address = ` + resStruct.Config.Interface.Addresses.V4 + `/32
-Address = ` + resStruct.Config.Interface.Addresses.V6 + `/128
-MTU = 1280
-
-Table = 300
-
-PreUp = ip rule add oif %i lookup 300
-PostDown = ip rule del oif %i lookup 300
-PreUp = ip -6 rule add oif %i lookup 300
-PostDown = ip -6 rule del oif %i lookup 300
-
-PreUp = ip rule add fwmark 32975 lookup 300
-PostDown = ip rule del fwmark 32975 lookup 300
-PreUp = ip -6 rule add fwmark 32975 lookup 300
-PostDown = ip -6 rule del fwmark 32975 lookup 300
-
-#PreUp = ip rule add from ` + resStruct.Config.Interface.Addresses.V4 + `/32 lookup 300
-#PostDown = ip rule del from ` + resStruct.Config.Interface.Addresses.V4 + `/32 lookup 300
-#PreUp = ip -6 rule add from ` + resStruct.Config.Interface.Addresses.V6 + `/128 lookup 300
-#PostDown = ip -6 rule del from ` + resStruct.Config.Interface.Addresses.V6 + `/128 lookup 300
-# Alternative
+` + wgLines("Address", interfaceAddresses(resStruct, options)) + dns + `MTU = ` + strconv.Itoa(options.MTU) + `
+` + gatewayComments(resStruct, options) + fallbackComments(resStruct, options) + interfaceExtra + routing.interfaceLines(resStruct, options, host, port) + `
+[Peer]
+PublicKey = ` + resStruct.Config.Peers[0].PublicKey + `
+` + policyComments(resStruct, options) + `AllowedIPs = ` + strings.Join(options.AllowedIPs, ", ") + `
+Endpoint = ` + net.JoinHostPort(host, strconv.Itoa(port)) + `
+` + keepaliveLine("PersistentKeepalive", options))
+	body = []byte(inStr)
+	return
+}
 
-PostUp = iptables -t mangle -A OUTPUT -s ` + resStruct.Config.Interface.Addresses.V4 + ` -j MARK --set-mark 32975
-PreDown = iptables -t mangle -D OUTPUT -s ` + resStruct.Config.Interface.Addresses.V4 + ` -j MARK --set-mark 32975
-PostUp = ip6tables -t mangle -A OUTPUT -s ` + resStruct.Config.Interface.Addresses.V6 + ` -j MARK --set-mark 32975
-PreDown = ip6tables -t mangle -D OUTPUT -s ` + resStruct.Config.Interface.Addresses.V6 + ` -j MARK --set-mark 32975
+func GenWgSetconf(resStruct C.Response, options C.GenerateOptions) (body []byte, err error) {
+	endpoint, err := joinAddress(resStruct, options)
+	if err != nil {
+		return
+	}
+	inStr := fmt.Sprint(`[Interface]
+PrivateKey = ` + resStruct.Config.PrivateKey + `
 
 [Peer]
 PublicKey = ` + resStruct.Config.Peers[0].PublicKey + `
-AllowedIPs = 0.0.0.0/0, ::/0
-Endpoint = ` + resStruct.Config.Peers[0].Endpoint.V4 + `
-`)
+` + policyComments(resStruct, options) + `AllowedIPs = ` + strings.Join(options.AllowedIPs, ", ") + `
+Endpoint = ` + endpoint + `
+` + keepaliveLine("PersistentKeepalive", options))
 	body = []byte(inStr)
 	return
 }
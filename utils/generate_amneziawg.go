@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// AmneziaWGParams holds the AmneziaWG junk packet and header obfuscation parameters
+type AmneziaWGParams struct {
+	Jc   int
+	Jmin int
+	Jmax int
+	S1   int
+	S2   int
+	H1   uint32
+	H2   uint32
+	H3   uint32
+	H4   uint32
+}
+
+// AmneziaWGPresets only use junk packets, since Cloudflare rejects changed S1/S2 and H1-H4 values
+var AmneziaWGPresets = map[string]AmneziaWGParams{
+	"light":  {Jc: 4, Jmin: 40, Jmax: 70, H1: 1, H2: 2, H3: 3, H4: 4},
+	"medium": {Jc: 8, Jmin: 40, Jmax: 250, H1: 1, H2: 2, H3: 3, H4: 4},
+	"heavy":  {Jc: 16, Jmin: 50, Jmax: 1000, H1: 1, H2: 2, H3: 3, H4: 4},
+}
+
+// CloudflareCompatible reports whether the header parameters are left as plain WireGuard ones
+func (p AmneziaWGParams) CloudflareCompatible() bool {
+	return p.S1 == 0 && p.S2 == 0 && p.H1 == 1 && p.H2 == 2 && p.H3 == 3 && p.H4 == 4
+}
+
+func (p AmneziaWGParams) Validate() error {
+	if p.Jc < 1 || p.Jc > 128 {
+		return errors.New("Jc must be between 1 and 128")
+	}
+	if p.Jmin < 0 || p.Jmin >= p.Jmax {
+		return errors.New("Jmin must be at least 0 and less than Jmax")
+	}
+	if p.Jmax > 1280 {
+		return errors.New("Jmax must be at most 1280")
+	}
+	if p.S1 < 0 || p.S1 > 1132 {
+		return errors.New("S1 must be between 0 and 1132")
+	}
+	if p.S2 < 0 || p.S2 > 1188 {
+		return errors.New("S2 must be between 0 and 1188")
+	}
+	if p.S1 != 0 && p.S1+56 == p.S2 {
+		return errors.New("S1 + 56 must not be equal to S2")
+	}
+	headers := []uint32{p.H1, p.H2, p.H3, p.H4}
+	for i, h := range headers {
+		if h == 0 {
+			return fmt.Errorf("H%d must be greater than 0", i+1)
+		}
+		for j := i + 1; j < len(headers); j++ {
+			if h == headers[j] {
+				return fmt.Errorf("H%d and H%d must be different", i+1, j+1)
+			}
+		}
+	}
+	return nil
+}
+
+func GenAmneziaWG(resStruct C.Response, options C.GenerateOptions, routing WgQuickRouting, params AmneziaWGParams) (body []byte, err error) {
+	if err = params.Validate(); err != nil {
+		return
+	}
+	return genWgQuick(resStruct, options, routing, `Jc = `+strconv.Itoa(params.Jc)+`
+Jmin = `+strconv.Itoa(params.Jmin)+`
+Jmax = `+strconv.Itoa(params.Jmax)+`
+S1 = `+strconv.Itoa(params.S1)+`
+S2 = `+strconv.Itoa(params.S2)+`
+H1 = `+strconv.FormatUint(uint64(params.H1), 10)+`
+H2 = `+strconv.FormatUint(uint64(params.H2), 10)+`
+H3 = `+strconv.FormatUint(uint64(params.H3), 10)+`
+H4 = `+strconv.FormatUint(uint64(params.H4), 10)+`
+`)
+}
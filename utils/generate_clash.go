@@ -0,0 +1,63 @@
+package utils
+
+import (
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+func GenClash(resStruct C.Response, options C.GenerateOptions, name string, fullProfile bool) (body []byte, err error) {
+	server, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return
+	}
+	config := C.Clash{
+		Proxies: []C.ClashProxy{
+			{
+				Name:                name,
+				Type:                "wireguard",
+				Server:              server,
+				Port:                port,
+				PrivateKey:          resStruct.Config.PrivateKey,
+				PublicKey:           resStruct.Config.Peers[0].PublicKey,
+				AllowedIPs:          options.AllowedIPs,
+				Reserved:            resStruct.Config.ReservedDec,
+				MTU:                 options.MTU,
+				PersistentKeepalive: options.Keepalive,
+				UDP:                 true,
+				RemoteDNSResolve:    len(options.DNS) > 0,
+				DNS:                 options.DNS,
+			},
+		},
+	}
+	if hasIPv4(options) {
+		config.Proxies[0].IP = resStruct.Config.Interface.Addresses.V4
+	}
+	if hasIPv6(options) {
+		config.Proxies[0].IPv6 = resStruct.Config.Interface.Addresses.V6
+	}
+
+	if fullProfile {
+		const group string = "PROXY"
+		config.MixedPort = 7890
+		config.Mode = "rule"
+		config.LogLevel = "info"
+		config.IPv6 = hasIPv6(options)
+		config.ProxyGroups = []C.ClashProxyGroup{
+			{
+				Name:    group,
+				Type:    "select",
+				Proxies: []string{name, "DIRECT"},
+			},
+		}
+		config.Rules = []string{
+			"IP-CIDR,127.0.0.0/8,DIRECT,no-resolve",
+			"IP-CIDR,10.0.0.0/8,DIRECT,no-resolve",
+			"IP-CIDR,172.16.0.0/12,DIRECT,no-resolve",
+			"IP-CIDR,192.168.0.0/16,DIRECT,no-resolve",
+			"IP-CIDR6,fc00::/7,DIRECT,no-resolve",
+			"MATCH," + group,
+		}
+	}
+
+	body, err = marshalYaml(config)
+	return
+}
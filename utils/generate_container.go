@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+func gluetunEnvironment(resStruct C.Response, options C.GenerateOptions) ([]string, error) {
+	// gluetun needs an IP, so the default domain endpoint becomes an address of the enabled families
+	if options.Endpoint == "domain" {
+		options.Endpoint = "auto"
+	}
+	host, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return nil, err
+	}
+	if !isIPEndpoint(host) {
+		return nil, errors.New("gluetun requires an IP endpoint, use 'ipv4'/'ipv6' endpoint or an IP 'host:port'")
+	}
+	environment := []string{
+		"VPN_SERVICE_PROVIDER=custom",
+		"VPN_TYPE=wireguard",
+		"WIREGUARD_ENDPOINT_IP=" + host,
+		"WIREGUARD_ENDPOINT_PORT=" + strconv.Itoa(port),
+		"WIREGUARD_PUBLIC_KEY=" + resStruct.Config.Peers[0].PublicKey,
+		"WIREGUARD_PRIVATE_KEY=" + resStruct.Config.PrivateKey,
+		"WIREGUARD_ADDRESSES=" + strings.Join(interfaceAddresses(resStruct, options), ","),
+		"WIREGUARD_ALLOWED_IPS=" + strings.Join(options.AllowedIPs, ","),
+		"WIREGUARD_MTU=" + strconv.Itoa(options.MTU),
+	}
+	if options.Keepalive != 0 {
+		environment = append(environment, "WIREGUARD_PERSISTENT_KEEPALIVE_INTERVAL="+strconv.Itoa(options.Keepalive)+"s")
+	}
+	return environment, nil
+}
+
+func GenGluetun(resStruct C.Response, options C.GenerateOptions) (body []byte, err error) {
+	environment, err := gluetunEnvironment(resStruct, options)
+	if err != nil {
+		return
+	}
+	body = []byte(strings.Join(environment, "\n") + "\n")
+	return
+}
+
+func GenCompose(resStruct C.Response, options C.GenerateOptions, service string) (body []byte, err error) {
+	environment, err := gluetunEnvironment(resStruct, options)
+	if err != nil {
+		return
+	}
+	compose := C.Compose{
+		Services: map[string]C.ComposeService{
+			service: {
+				Image:         "qmcgaw/gluetun",
+				ContainerName: service,
+				CapAdd:        []string{"NET_ADMIN"},
+				Devices:       []string{"/dev/net/tun:/dev/net/tun"},
+				Environment:   environment,
+				Restart:       "unless-stopped",
+			},
+		},
+	}
+	body, err = marshalYaml(compose)
+	return
+}
+
+func GenKubernetesSecret(resStruct C.Response, options C.GenerateOptions, name string, namespace string, key string) (body []byte, err error) {
+	wgQuick, err := GenWgQuick(resStruct, options, DefaultWgQuickRouting())
+	if err != nil {
+		return
+	}
+	secret := C.KubernetesSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Type:       "Opaque",
+		Data:       map[string]string{key: base64.StdEncoding.EncodeToString(wgQuick)},
+	}
+	secret.Metadata.Name = name
+	secret.Metadata.Namespace = namespace
+	body, err = marshalYaml(secret)
+	return
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// interfaceNameMaxLength is the longest Linux interface name, IFNAMSIZ (16) less the terminating NUL
+const interfaceNameMaxLength int = 15
+
+var netnsNameValid = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// GenNetns generates a shell script which runs the tunnel inside the network namespace name,
+// leaving the host routing untouched. The interface is configured in the host namespace
+// before it is moved, so that its UDP socket keeps using the host network
+func GenNetns(resStruct C.Response, options C.GenerateOptions, name string, iface string) (body []byte, err error) {
+	if !netnsNameValid.MatchString(name) {
+		return nil, errors.New("network namespace name may only contain letters, digits, '_', '.' and '-'")
+	}
+	if !netnsNameValid.MatchString(iface) || len(iface) > interfaceNameMaxLength {
+		return nil, errors.New("interface name must be 1-" + strconv.Itoa(interfaceNameMaxLength) + " letters, digits, '_', '.' or '-'")
+	}
+	setconf, err := GenWgSetconf(resStruct, options)
+	if err != nil {
+		return
+	}
+
+	var addresses, routes, resolv string
+	for _, address := range interfaceAddresses(resStruct, options) {
+		addresses += "\tip -n \"$NETNS\" address add " + address + " dev \"$IFACE\"\n"
+	}
+	for _, cidr := range options.AllowedIPs {
+		routes += "\tip -n \"$NETNS\" route add " + cidr + " dev \"$IFACE\"\n"
+	}
+	if len(options.DNS) > 0 {
+		resolv = "\tmkdir -p \"/etc/netns/$NETNS\"\n"
+		resolv += "\tprintf 'nameserver %s\\n' " + strings.Join(options.DNS, " ") + " > \"/etc/netns/$NETNS/resolv.conf\"\n"
+	} else {
+		resolv = "\t# No DNS servers configured, the namespace uses the resolv.conf of the host\n"
+	}
+
+	inStr := `#!/bin/sh
+# Runs applications through WARP inside a network namespace, the host routing is left untouched.
+# Usage: sudo sh <script> up|down|exec <command> [args...]
+# Commands run by exec are started as root, use 'exec sudo -u <user> <command>' to drop privileges
+set -e
+
+NETNS=` + name + `
+IFACE=` + iface + `
+
+up() {
+	ip netns add "$NETNS"
+	ip link add "$IFACE" type wireguard
+	wg setconf "$IFACE" /dev/stdin <<'EOF'
+` + string(setconf) + `EOF
+	ip link set "$IFACE" netns "$NETNS"
+` + addresses + `	ip -n "$NETNS" link set lo up
+	ip -n "$NETNS" link set "$IFACE" mtu ` + strconv.Itoa(options.MTU) + ` up
+` + routes + resolv + `}
+
+down() {
+	ip netns delete "$NETNS"
+	rm -rf "/etc/netns/$NETNS"
+}
+
+case "$1" in
+up)
+	up
+	;;
+down)
+	down
+	;;
+exec)
+	shift
+	exec ip netns exec "$NETNS" "$@"
+	;;
+*)
+	echo "Usage: $0 up|down|exec <command> [args...]" >&2
+	exit 1
+	;;
+esac
+`
+	body = []byte(inStr)
+	return
+}
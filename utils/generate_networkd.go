@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+func GenNetworkd(resStruct C.Response, options C.GenerateOptions, name string) (files []GeneratedFile, err error) {
+	endpoint, err := joinAddress(resStruct, options)
+	if err != nil {
+		return
+	}
+	table := strconv.Itoa(routeTable)
+	// route what the host sends from the tunnel addresses through the tunnel
+	var sourceRules string
+	if hasIPv4(options) {
+		sourceRules += "\n[RoutingPolicyRule]\nFrom = " + resStruct.Config.Interface.Addresses.V4 + "/32\nTable = " + table + "\n"
+	}
+	if hasIPv6(options) {
+		sourceRules += "\n[RoutingPolicyRule]\nFrom = " + resStruct.Config.Interface.Addresses.V6 + "/128\nTable = " + table + "\n"
+	}
+
+	netdev := fmt.Sprint(`# The private key is readable by systemd-networkd only if this file
+# is owned by root:systemd-network with mode 0640
+[NetDev]
+Name = ` + name + `
+Kind = wireguard
+MTUBytes = ` + strconv.Itoa(options.MTU) + `
+
+[WireGuard]
+PrivateKey = ` + resStruct.Config.PrivateKey + `
+RouteTable = ` + table + `
+
+[WireGuardPeer]
+PublicKey = ` + resStruct.Config.Peers[0].PublicKey + `
+` + policyComments(resStruct, options) + wgLines("AllowedIPs", options.AllowedIPs) + `Endpoint = ` + endpoint + `
+` + keepaliveLine("PersistentKeepalive", options))
+
+	network := fmt.Sprint(`[Match]
+Name = ` + name + `
+
+[Network]
+` + wgLines("Address", interfaceAddresses(resStruct, options)) + wgLines("DNS", options.DNS) + `
+[RoutingPolicyRule]
+OutgoingInterface = ` + name + `
+Table = ` + table + `
+Family = ` + options.Family + `
+` + sourceRules)
+
+	files = []GeneratedFile{
+		{Extension: ".netdev", Body: []byte(netdev)},
+		{Extension: ".network", Body: []byte(network)},
+	}
+	return
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+func GenNetworkManager(resStruct C.Response, options C.GenerateOptions, name string) (body []byte, err error) {
+	endpoint, err := joinAddress(resStruct, options)
+	if err != nil {
+		return
+	}
+	table := strconv.Itoa(routeTable)
+	dnsV4, dnsV6 := dnsByFamily(options)
+
+	var keepalive string
+	if options.Keepalive != 0 {
+		keepalive = "persistent-keepalive=" + strconv.Itoa(options.Keepalive) + "\n"
+	}
+
+	inStr := fmt.Sprint(`[connection]
+id=` + name + `
+uuid=` + NameUUID(resStruct.ID+name) + `
+type=wireguard
+interface-name=` + name + `
+
+[wireguard]
+private-key=` + resStruct.Config.PrivateKey + `
+mtu=` + strconv.Itoa(options.MTU) + `
+ip4-auto-default-route=false
+ip6-auto-default-route=false
+
+[wireguard-peer.` + resStruct.Config.Peers[0].PublicKey + `]
+endpoint=` + endpoint + `
+` + policyComments(resStruct, options) + `allowed-ips=` + strings.Join(options.AllowedIPs, ";") + `;
+` + keepalive)
+
+	if hasIPv4(options) {
+		inStr += fmt.Sprint(`
+[ipv4]
+method=manual
+address1=` + resStruct.Config.Interface.Addresses.V4 + `/32
+dns=` + strings.Join(append(dnsV4, ""), ";") + `
+route-table=` + table + `
+routing-rule1=priority 31000 from 0.0.0.0/0 oif ` + name + ` table ` + table + `
+routing-rule2=priority 31001 from ` + resStruct.Config.Interface.Addresses.V4 + `/32 table ` + table + `
+`)
+	} else {
+		inStr += "\n[ipv4]\nmethod=disabled\n"
+	}
+
+	if hasIPv6(options) {
+		inStr += fmt.Sprint(`
+[ipv6]
+method=manual
+address1=` + resStruct.Config.Interface.Addresses.V6 + `/128
+dns=` + strings.Join(append(dnsV6, ""), ";") + `
+route-table=` + table + `
+routing-rule1=priority 31000 from ::/0 oif ` + name + ` table ` + table + `
+routing-rule2=priority 31001 from ` + resStruct.Config.Interface.Addresses.V6 + `/128 table ` + table + `
+`)
+	} else {
+		inStr += "\n[ipv6]\nmethod=disabled\n"
+	}
+
+	body = []byte(inStr)
+	return
+}
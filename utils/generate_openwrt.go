@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// uciLines renders one line per value, made of prefix and the quoted value
+func uciLines(prefix string, values []string) string {
+	var lines string
+	for _, value := range values {
+		lines += prefix + "'" + value + "'\n"
+	}
+	return lines
+}
+
+func GenOpenWrt(resStruct C.Response, options C.GenerateOptions, name string) (body []byte, err error) {
+	host, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return
+	}
+
+	var keepalive string
+	if options.Keepalive != 0 {
+		keepalive = "\toption persistent_keepalive '" + strconv.Itoa(options.Keepalive) + "'\n"
+	}
+
+	inStr := fmt.Sprint(`config interface '` + name + `'
+	option proto 'wireguard'
+	option private_key '` + resStruct.Config.PrivateKey + `'
+` + uciLines("\tlist addresses ", interfaceAddresses(resStruct, options)) + uciLines("\tlist dns ", options.DNS) + `	option mtu '` + strconv.Itoa(options.MTU) + `'
+
+config wireguard_` + name + ` '` + name + `_peer'
+	option description 'Cloudflare WARP'
+	option public_key '` + resStruct.Config.Peers[0].PublicKey + `'
+	option endpoint_host '` + host + `'
+	option endpoint_port '` + strconv.Itoa(port) + `'
+` + policyComments(resStruct, options) + uciLines("\tlist allowed_ips ", options.AllowedIPs) + `	option route_allowed_ips '1'
+` + keepalive)
+	body = []byte(inStr)
+	return
+}
+
+func GenOpenWrtBatch(resStruct C.Response, options C.GenerateOptions, name string) (body []byte, err error) {
+	host, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return
+	}
+	peer := "network." + name + "_peer"
+
+	var keepalive string
+	if options.Keepalive != 0 {
+		keepalive = "set " + peer + ".persistent_keepalive='" + strconv.Itoa(options.Keepalive) + "'\n"
+	}
+
+	inStr := fmt.Sprint(`#!/bin/sh
+uci -q delete network.` + name + `
+uci -q delete ` + peer + `
+
+uci batch <<EOF
+set network.` + name + `=interface
+set network.` + name + `.proto='wireguard'
+set network.` + name + `.private_key='` + resStruct.Config.PrivateKey + `'
+` + uciLines("add_list network."+name+".addresses=", interfaceAddresses(resStruct, options)) + uciLines("add_list network."+name+".dns=", options.DNS) + `set network.` + name + `.mtu='` + strconv.Itoa(options.MTU) + `'
+set ` + peer + `=wireguard_` + name + `
+set ` + peer + `.description='Cloudflare WARP'
+set ` + peer + `.public_key='` + resStruct.Config.Peers[0].PublicKey + `'
+set ` + peer + `.endpoint_host='` + host + `'
+set ` + peer + `.endpoint_port='` + strconv.Itoa(port) + `'
+` + uciLines("add_list "+peer+".allowed_ips=", options.AllowedIPs) + `set ` + peer + `.route_allowed_ips='1'
+` + keepalive + `commit network
+EOF
+
+/etc/init.d/network reload
+`)
+	body = []byte(inStr)
+	return
+}
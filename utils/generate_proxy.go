@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// HTTPProxyTargets lists the supported outputs of the HTTP proxy generator
+var HTTPProxyTargets = []string{"pac", "xray", "sing-box", "clash"}
+
+// splitHTTPProxy returns the address of the account HTTP proxy service
+func splitHTTPProxy(resStruct C.Response) (host string, port int, err error) {
+	if resStruct.Config.Services.HTTPProxy == "" {
+		return "", 0, errors.New("account has no HTTP proxy service")
+	}
+	host, portStr, err := net.SplitHostPort(resStruct.Config.Services.HTTPProxy)
+	if err != nil {
+		return "", 0, err
+	}
+	port, err = strconv.Atoi(portStr)
+	return
+}
+
+// HTTPProxyInTunnel reports whether the account HTTP proxy service has a
+// private address, which is only reachable through a connected WARP tunnel
+func HTTPProxyInTunnel(resStruct C.Response) bool {
+	host, _, err := splitHTTPProxy(resStruct)
+	if err != nil {
+		return false
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	for _, cidr := range privateCIDRs {
+		if prefix, err := ParsePrefix(cidr); err == nil && prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenHTTPProxy generates a config using the account HTTP proxy service instead of a WireGuard tunnel
+func GenHTTPProxy(resStruct C.Response, target string, tag string) (file GeneratedFile, err error) {
+	host, port, err := splitHTTPProxy(resStruct)
+	if err != nil {
+		return
+	}
+
+	switch target {
+	case "pac":
+		file.Extension = ".pac"
+		file.Body = genPAC(resStruct, net.JoinHostPort(host, strconv.Itoa(port)))
+	case "xray":
+		outbound := C.XrayHTTPOutbound{Protocol: "http", Tag: tag}
+		outbound.Settings.Servers = []C.XrayHTTPServer{{Address: host, Port: port}}
+		file.Extension = ".xray.json"
+		file.Body, err = json.MarshalIndent(outbound, "", "    ")
+	case "sing-box":
+		file.Extension = ".sing-box.json"
+		file.Body, err = json.MarshalIndent(C.SingHTTPOutbound{Type: "http", Tag: tag, Server: host, ServerPort: port}, "", "    ")
+	case "clash":
+		config := struct {
+			Proxies []C.ClashHTTPProxy `yaml:"proxies"`
+		}{Proxies: []C.ClashHTTPProxy{{Name: tag, Type: "http", Server: host, Port: port}}}
+		file.Extension = ".clash.yaml"
+		file.Body, err = marshalYaml(config)
+	default:
+		err = errors.New("unsupported HTTP proxy target " + target)
+	}
+	return
+}
+
+// genPAC generates a proxy auto-config file sending everything but local
+// names and the Zero Trust fallback domains to the proxy. Requests are not
+// retried directly when the proxy is unreachable, so they cannot leak out of it
+func genPAC(resStruct C.Response, proxy string) []byte {
+	var direct string
+	for _, suffix := range fallbackDomains(resStruct) {
+		direct += `
+    if (host == "` + suffix + `" || dnsDomainIs(host, ".` + suffix + `")) {
+        return "DIRECT";
+    }`
+	}
+
+	var private []string
+	for _, cidr := range privateCIDRs {
+		prefix, err := ParsePrefix(cidr)
+		if err != nil || !prefix.Addr().Is4() {
+			continue
+		}
+		mask := net.CIDRMask(prefix.Bits(), 32)
+		private = append(private, `isInNet(host, "`+prefix.Addr().String()+`", "`+net.IP(mask).String()+`")`)
+	}
+
+	return []byte(`function FindProxyForURL(url, host) {
+    if (isPlainHostName(host)) {
+        return "DIRECT";
+    }` + direct + `
+    if (/^\d+\.\d+\.\d+\.\d+$/.test(host) && (` + strings.Join(private, " ||\n        ") + `)) {
+        return "DIRECT";
+    }
+    return "PROXY ` + proxy + `";
+}
+`)
+}
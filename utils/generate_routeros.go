@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// routerOSFamily is the menu prefix, tunnel address and DNS servers of one address family
+type routerOSFamily struct {
+	menu    string
+	address string
+	length  string
+	dns     []string
+}
+
+func GenRouterOS(resStruct C.Response, options C.GenerateOptions, name string) (body []byte, err error) {
+	host, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return
+	}
+	comment := `comment="` + name + `"`
+	keepalive := "persistent-keepalive=" + strconv.Itoa(options.Keepalive) + "s"
+
+	dnsV4, dnsV6 := dnsByFamily(options)
+	var families []routerOSFamily
+	if hasIPv4(options) {
+		families = append(families, routerOSFamily{"/ip", resStruct.Config.Interface.Addresses.V4, "/32", dnsV4})
+	}
+	if hasIPv6(options) {
+		families = append(families, routerOSFamily{"/ipv6", resStruct.Config.Interface.Addresses.V6, "/128", dnsV6})
+	}
+
+	inStr := fmt.Sprint(`# Cloudflare WARP for RouterOS v7, safe to import repeatedly
+# Add LAN hosts to the '` + name + `-clients' address lists to route them through WARP
+
+/interface wireguard
+:if ([:len [find name="` + name + `"]] = 0) do={ add name="` + name + `" }
+set [find name="` + name + `"] mtu=` + strconv.Itoa(options.MTU) + ` private-key="` + resStruct.Config.PrivateKey + `"
+
+/interface wireguard peers
+remove [find interface="` + name + `"]
+add interface="` + name + `" public-key="` + resStruct.Config.Peers[0].PublicKey + `" endpoint-address=` + host + ` endpoint-port=` + strconv.Itoa(port) + ` allowed-address=` + strings.Join(options.AllowedIPs, ",") + ` ` + keepalive + ` ` + comment + `
+`)
+
+	for _, family := range families {
+		inStr += `
+` + family.menu + ` address
+remove [find interface="` + name + `"]
+add address=` + family.address + family.length + ` interface="` + name + `" ` + Ternary(family.menu == "/ipv6", "advertise=no ", "") + comment + `
+`
+	}
+
+	inStr += `
+/routing table
+:if ([:len [find name="` + name + `"]] = 0) do={ add name="` + name + `" fib }
+`
+	for _, family := range families {
+		inStr += `
+` + family.menu + ` route
+remove [find routing-table="` + name + `"]
+add dst-address=` + Ternary(family.menu == "/ipv6", "::/0", "0.0.0.0/0") + ` gateway="` + name + `" routing-table="` + name + `" ` + comment + `
+`
+	}
+
+	inStr += `
+/routing rule
+remove [find ` + comment + `]
+`
+	for _, family := range families {
+		inStr += `add src-address=` + family.address + family.length + ` action=lookup-only-in-table table="` + name + `" ` + comment + `
+`
+	}
+
+	for _, family := range families {
+		inStr += `
+` + family.menu + ` firewall mangle
+remove [find ` + comment + `]
+add chain=output src-address=` + family.address + ` action=mark-routing new-routing-mark="` + name + `" passthrough=no ` + comment + `
+add chain=prerouting src-address-list="` + name + `-clients" action=mark-routing new-routing-mark="` + name + `" passthrough=no ` + comment + `
+`
+		for _, server := range family.dns {
+			inStr += `add chain=output dst-address=` + server + ` action=mark-routing new-routing-mark="` + name + `" passthrough=no ` + comment + `
+`
+		}
+	}
+
+	for _, family := range families {
+		inStr += `
+` + family.menu + ` firewall nat
+remove [find ` + comment + `]
+add chain=srcnat out-interface="` + name + `" action=masquerade ` + comment + `
+`
+	}
+
+	if len(options.DNS) > 0 {
+		inStr += `
+# The router resolver queries the DNS servers through WARP
+/ip dns
+set servers=` + strings.Join(options.DNS, ",") + `
+`
+	}
+	body = []byte(inStr)
+	return
+}
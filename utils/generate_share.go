@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+	E "github.com/ArchiveNetwork/wgcf-cli/enum"
+)
+
+// ShareLinkDialects lists the supported share link flavours
+var ShareLinkDialects = []string{"v2rayn", "nekobox", "hiddify"}
+
+// ShareLink builds a single share URI of the given dialect for the endpoint selected by options
+func ShareLink(resStruct C.Response, options C.GenerateOptions, dialect string, name string) (string, error) {
+	server, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return "", err
+	}
+	host := net.JoinHostPort(server, strconv.Itoa(port))
+	privateKey := resStruct.Config.PrivateKey
+	publicKey := resStruct.Config.Peers[0].PublicKey
+	address := strings.Join(interfaceAddresses(resStruct, options), ",")
+	reserved := joinInts(resStruct.Config.ReservedDec, ",")
+	mtu := strconv.Itoa(options.MTU)
+
+	var scheme, user string
+	query := url.Values{}
+	switch dialect {
+	case "v2rayn":
+		scheme, user = "wireguard", privateKey
+		query.Set("publickey", publicKey)
+		query.Set("address", address)
+		query.Set("reserved", reserved)
+		query.Set("mtu", mtu)
+	case "nekobox":
+		scheme, user = "wg", privateKey
+		query.Set("public_key", publicKey)
+		query.Set("local_address", address)
+		query.Set("reserved", reserved)
+		query.Set("mtu", mtu)
+	case "hiddify":
+		scheme = "wg"
+		query.Set("pk", privateKey)
+		query.Set("peer_pk", publicKey)
+		query.Set("local_address", address)
+		query.Set("reserved", reserved)
+		query.Set("mtu", mtu)
+	default:
+		return "", errors.New("unsupported share link dialect " + dialect)
+	}
+
+	link := scheme + "://"
+	if user != "" {
+		link += url.QueryEscape(user) + "@"
+	}
+	link += host + "/?" + query.Encode() + "#" + url.PathEscape(name)
+	return link, nil
+}
+
+// GenShareLinks generates a link per endpoint type, skipping the types of
+// disabled families and the endpoints the account does not have
+func GenShareLinks(resStruct C.Response, options C.GenerateOptions, dialect string, name string) (body []byte, err error) {
+	for endpointType := E.Domain; endpointType <= E.IPv6; endpointType++ {
+		if endpointType == E.IPv4 && !hasIPv4(options) || endpointType == E.IPv6 && !hasIPv6(options) {
+			continue
+		}
+		var link string
+		linkOptions := options
+		linkOptions.Endpoint = endpointType.String()
+		if _, _, err = SelectEndpoint(resStruct, linkOptions); err != nil {
+			continue
+		}
+		if link, err = ShareLink(resStruct, linkOptions, dialect, name+"-"+endpointType.String()); err != nil {
+			return nil, err
+		}
+		body = append(body, link+"\n"...)
+	}
+	if len(body) == 0 {
+		return nil, errors.New("account has no endpoint for share links")
+	}
+	return body, nil
+}
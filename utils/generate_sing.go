@@ -0,0 +1,180 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// parseSingVersion parses a sing-box version such as '1.11' or '1.10.7' into its major and minor parts
+func parseSingVersion(version string) (major int, minor int, err error) {
+	slice := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(slice) < 2 {
+		return 0, 0, errors.New("invalid sing-box version " + version)
+	}
+	if major, err = strconv.Atoi(slice[0]); err != nil {
+		return 0, 0, errors.New("invalid sing-box version " + version)
+	}
+	if minor, err = strconv.Atoi(slice[1]); err != nil {
+		return 0, 0, errors.New("invalid sing-box version " + version)
+	}
+	return
+}
+
+func singAtLeast(major, minor, wantMajor, wantMinor int) bool {
+	return major > wantMajor || (major == wantMajor && minor >= wantMinor)
+}
+
+func GenSing(resStruct C.Response, options C.GenerateOptions, tag string, version string, fullProfile bool, inbound string) (body []byte, err error) {
+	major, minor, err := parseSingVersion(version)
+	if err != nil {
+		return
+	}
+	legacy := !singAtLeast(major, minor, 1, 11)
+
+	server, port, err := SelectEndpoint(resStruct, options)
+	if err != nil {
+		return
+	}
+	address := interfaceAddresses(resStruct, options)
+
+	var wireguard any
+	if legacy && fullTunnel(options) {
+		wireguard = C.Sing{
+			Type:          "wireguard",
+			Tag:           tag,
+			Server:        server,
+			ServerPort:    port,
+			LocalAddress:  address,
+			PrivateKey:    resStruct.Config.PrivateKey,
+			PeerPublicKey: resStruct.Config.Peers[0].PublicKey,
+			Reserved:      resStruct.Config.ReservedDec,
+			MTU:           options.MTU,
+		}
+	} else if legacy {
+		// the single peer form routes everything, split tunnels need the peers form
+		wireguard = C.Sing{
+			Type:         "wireguard",
+			Tag:          tag,
+			LocalAddress: address,
+			PrivateKey:   resStruct.Config.PrivateKey,
+			Peers: []C.SingPeer{
+				{
+					Server:     server,
+					ServerPort: port,
+					PublicKey:  resStruct.Config.Peers[0].PublicKey,
+					AllowedIPs: options.AllowedIPs,
+					Reserved:   resStruct.Config.ReservedDec,
+				},
+			},
+			MTU: options.MTU,
+		}
+	} else {
+		wireguard = C.SingEndpoint{
+			Type:       "wireguard",
+			Tag:        tag,
+			MTU:        options.MTU,
+			Address:    address,
+			PrivateKey: resStruct.Config.PrivateKey,
+			Peers: []C.SingEndpointPeer{
+				{
+					Address:                     server,
+					Port:                        port,
+					PublicKey:                   resStruct.Config.Peers[0].PublicKey,
+					AllowedIPs:                  options.AllowedIPs,
+					PersistentKeepaliveInterval: options.Keepalive,
+					Reserved:                    resStruct.Config.ReservedDec,
+				},
+			},
+		}
+	}
+
+	if !fullProfile {
+		body, err = json.MarshalIndent(wireguard, "", "    ")
+		return
+	}
+
+	profile := C.SingProfile{
+		DNS: C.SingDNS{
+			Servers: []C.SingDNSServer{
+				{Tag: "local", Address: "local", Detour: "direct"},
+			},
+			Rules: []C.SingDNSRule{
+				{Outbound: "any", Server: "local"},
+			},
+			Final: "local",
+		},
+		Outbounds: []any{C.SingOutbound{Type: "direct", Tag: "direct"}},
+		Route: C.SingRoute{
+			Final:               tag,
+			AutoDetectInterface: true,
+		},
+	}
+	profile.Log.Level = "info"
+	if doh, _, err := GatewayDNS(resStruct); options.GatewayDNS && err == nil {
+		profile.DNS.Servers = append(profile.DNS.Servers, C.SingDNSServer{Tag: "gateway", Address: doh, AddressResolver: "local", Detour: tag})
+		profile.DNS.Final = "gateway"
+	}
+	for i, server := range options.DNS {
+		remote := "remote-" + strconv.Itoa(i+1)
+		profile.DNS.Servers = append(profile.DNS.Servers, C.SingDNSServer{Tag: remote, Address: server, Detour: tag})
+		if profile.DNS.Final == "local" {
+			profile.DNS.Final = remote
+		}
+	}
+
+	if suffixes := fallbackDomains(resStruct); len(suffixes) > 0 {
+		server := "local"
+		if options.FallbackResolver != "local" {
+			server = "fallback"
+			profile.DNS.Servers = append(profile.DNS.Servers, C.SingDNSServer{Tag: server, Address: options.FallbackResolver, Detour: "direct"})
+		}
+		profile.DNS.Rules = append(profile.DNS.Rules, C.SingDNSRule{DomainSuffix: suffixes, Server: server})
+	}
+
+	switch inbound {
+	case "mixed":
+		profile.Inbounds = []C.SingInbound{{Type: "mixed", Tag: "mixed-in", Listen: "127.0.0.1", ListenPort: 2080}}
+	case "tun":
+		tun := C.SingInbound{Type: "tun", Tag: "tun-in", AutoRoute: true, StrictRoute: true}
+		if singAtLeast(major, minor, 1, 10) {
+			tun.Address = []string{"172.19.0.1/30", "fdfe:dcba:9876::1/126"}
+		} else {
+			tun.Inet4Address = "172.19.0.1/30"
+			tun.Inet6Address = "fdfe:dcba:9876::1/126"
+		}
+		profile.Inbounds = []C.SingInbound{tun}
+	default:
+		return nil, errors.New("unsupported sing-box inbound " + inbound)
+	}
+
+	if legacy {
+		for i := range profile.Inbounds {
+			profile.Inbounds[i].Sniff = true
+		}
+		profile.Outbounds = append([]any{wireguard}, append(profile.Outbounds, C.SingOutbound{Type: "dns", Tag: "dns-out"})...)
+		profile.Route.Rules = []C.SingRouteRule{
+			{Protocol: "dns", Outbound: "dns-out"},
+			{IPIsPrivate: true, Outbound: "direct"},
+		}
+	} else {
+		profile.Endpoints = []C.SingEndpoint{wireguard.(C.SingEndpoint)}
+		profile.Route.Rules = []C.SingRouteRule{
+			{Action: "sniff"},
+			{Protocol: "dns", Action: "hijack-dns"},
+			{IPIsPrivate: true, Outbound: "direct"},
+		}
+	}
+	if cidrs := policyDirectCIDRs(resStruct, options); len(cidrs) > 0 {
+		profile.Route.Rules = append(profile.Route.Rules, C.SingRouteRule{IPCIDR: cidrs, Outbound: "direct"})
+	}
+	if hosts := policyDirectDomains(resStruct, options); len(hosts) > 0 {
+		profile.Route.Rules = append(profile.Route.Rules, C.SingRouteRule{DomainSuffix: hosts, Outbound: "direct"})
+	}
+
+	body, err = json.MarshalIndent(profile, "", "    ")
+	return
+}
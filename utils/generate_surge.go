@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+func joinInts(values []int, sep string) string {
+	var slice []string
+	for _, v := range values {
+		slice = append(slice, strconv.Itoa(v))
+	}
+	return strings.Join(slice, sep)
+}
+
+func GenSurge(resStruct C.Response, options C.GenerateOptions, name string) (body []byte, err error) {
+	endpoint, err := joinAddress(resStruct, options)
+	if err != nil {
+		return
+	}
+	const section string = "warp"
+
+	var selfIP string
+	if hasIPv4(options) {
+		selfIP += "self-ip = " + resStruct.Config.Interface.Addresses.V4 + "\n"
+	}
+	if hasIPv6(options) {
+		selfIP += "self-ip-v6 = " + resStruct.Config.Interface.Addresses.V6 + "\n"
+	}
+	if len(options.DNS) > 0 {
+		selfIP += "dns-server = " + strings.Join(options.DNS, ", ") + "\n"
+	}
+	var keepalive string
+	if options.Keepalive != 0 {
+		keepalive = ", keepalive = " + strconv.Itoa(options.Keepalive)
+	}
+
+	inStr := fmt.Sprint(`[Proxy]
+` + name + ` = wireguard, section-name = ` + section + `
+
+[WireGuard ` + section + `]
+private-key = ` + resStruct.Config.PrivateKey + `
+` + selfIP + `mtu = ` + strconv.Itoa(options.MTU) + `
+peer = (public-key = ` + resStruct.Config.Peers[0].PublicKey + `, allowed-ips = "` + strings.Join(options.AllowedIPs, ", ") + `", endpoint = ` + endpoint + keepalive + `, client-id = ` + joinInts(resStruct.Config.ReservedDec, "/") + `)
+`)
+	body = []byte(inStr)
+	return
+}
+
+func GenLoon(resStruct C.Response, options C.GenerateOptions, name string) (body []byte, err error) {
+	endpoint, err := joinAddress(resStruct, options)
+	if err != nil {
+		return
+	}
+	var fields []string
+	if hasIPv4(options) {
+		fields = append(fields, "interface-ip="+resStruct.Config.Interface.Addresses.V4)
+	}
+	if hasIPv6(options) {
+		fields = append(fields, "interface-ipV6="+resStruct.Config.Interface.Addresses.V6)
+	}
+	fields = append(fields, `private-key="`+resStruct.Config.PrivateKey+`"`, "mtu="+strconv.Itoa(options.MTU))
+	dnsV4, dnsV6 := dnsByFamily(options)
+	if len(dnsV4) > 0 {
+		fields = append(fields, "dns="+dnsV4[0])
+	}
+	if len(dnsV6) > 0 {
+		fields = append(fields, "dnsV6="+dnsV6[0])
+	}
+	if options.Keepalive != 0 {
+		fields = append(fields, "keepalive="+strconv.Itoa(options.Keepalive))
+	}
+
+	inStr := fmt.Sprint(`[Proxy]
+` + name + ` = wireguard, ` + strings.Join(fields, ", ") + `, peers=[{public-key="` + resStruct.Config.Peers[0].PublicKey +
+		`", allowed-ips="` + strings.Join(options.AllowedIPs, ", ") + `", endpoint=` + endpoint +
+		`, reserved=[` + joinInts(resStruct.Config.ReservedDec, ",") + `]}]
+`)
+	body = []byte(inStr)
+	return
+}
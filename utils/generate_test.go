@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+	E "github.com/ArchiveNetwork/wgcf-cli/enum"
+)
+
+// testResponse loads the Zero Trust account fixture from testdata
+func testResponse(t *testing.T) C.Response {
+	body, err := os.ReadFile("testdata/account.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resStruct C.Response
+	if err = json.Unmarshal(body, &resStruct); err != nil {
+		t.Fatal(err)
+	}
+	return resStruct
+}
+
+// testOptions normalizes the default options after modify changed them
+func testOptions(t *testing.T, modify func(options *C.GenerateOptions)) C.GenerateOptions {
+	options := DefaultGenerateOptions()
+	if modify != nil {
+		modify(&options)
+	}
+	options, err := NormalizeOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return options
+}
+
+func TestGenRouterOSFamily(t *testing.T) {
+	resStruct := testResponse(t)
+	options := testOptions(t, func(options *C.GenerateOptions) {
+		options.Family = "ipv4"
+		options.DNS = []string{"1.1.1.1", "2606:4700:4700::1111"}
+	})
+	body, err := GenRouterOS(resStruct, options, "warp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := string(body)
+	if strings.Contains(config, "/ipv6") || strings.Contains(config, resStruct.Config.Interface.Addresses.V6) {
+		t.Errorf("Expected no IPv6 sections with the ipv4 family, but got:\n%s", config)
+	}
+	if !strings.Contains(config, "set servers=1.1.1.1\n") || !strings.Contains(config, "dst-address=1.1.1.1 action=mark-routing") {
+		t.Errorf("Expected the DNS servers to be set and routed through WARP, but got:\n%s", config)
+	}
+}
+
+func TestGenSingLegacySplitTunnel(t *testing.T) {
+	resStruct := testResponse(t)
+	options := testOptions(t, func(options *C.GenerateOptions) { options.Exclude = []string{"private"} })
+	body, err := GenSing(resStruct, options, "warp", "1.10", false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outbound C.Sing
+	if err = json.Unmarshal(body, &outbound); err != nil {
+		t.Fatal(err)
+	}
+	if len(outbound.Peers) != 1 || strings.Join(outbound.Peers[0].AllowedIPs, ",") != strings.Join(options.AllowedIPs, ",") {
+		t.Errorf("Expected the legacy outbound to keep AllowedIPs in peers, but got:\n%s", body)
+	}
+	if err = Validate(E.SingBox, GeneratedFile{Body: body}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGenShareLinksMissingEndpoint(t *testing.T) {
+	resStruct := testResponse(t)
+	resStruct.Config.Peers[0].Endpoint.V6 = ""
+	body, err := GenShareLinks(resStruct, testOptions(t, nil), "v2rayn", "WARP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := strings.Fields(string(body))
+	if len(links) != 2 || strings.Contains(string(body), "@:0/") || strings.Contains(string(body), "WARP-ipv6") {
+		t.Errorf("Expected the domain and ipv4 links only, but got:\n%s", body)
+	}
+}
+
+func TestGenWireGuardZipMissingEndpoint(t *testing.T) {
+	resStruct := testResponse(t)
+	resStruct.Config.Peers[0].Endpoint.V6 = ""
+	tunnels := []Tunnel{
+		{Name: "warp", Response: resStruct, Options: testOptions(t, nil)},
+		{Name: "v6only", Response: testResponse(t), Options: testOptions(t, func(options *C.GenerateOptions) { options.Family = "ipv6" })},
+	}
+	body, err := GenWireGuardZip(tunnels, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, file := range archive.File {
+		if file.Method != zip.Deflate || file.Modified.IsZero() {
+			t.Errorf("Expected %s to be deflated with a modification time", file.Name)
+		}
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, " ") != "warp-dn.conf warp-v4.conf v6only-dn.conf v6only-v6.conf" {
+		t.Errorf("Expected no config for a missing or disabled endpoint, but got: %v", names)
+	}
+}
+
+func TestGenWgQuickDefaultDNS(t *testing.T) {
+	resStruct := testResponse(t)
+	body, err := GenWgQuick(resStruct, testOptions(t, nil), DefaultWgQuickRouting())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(body), "DNS =") {
+		t.Errorf("Expected no DNS line by default, but got:\n%s", body)
+	}
+
+	options := testOptions(t, func(options *C.GenerateOptions) { options.DNS = []string{"1.1.1.1"} })
+	if body, err = GenWgQuick(resStruct, options, DefaultWgQuickRouting()); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(body), "DNS = 1.1.1.1\n") {
+		t.Errorf("Expected the DNS line of --dns, but got:\n%s", body)
+	}
+}
+
+func TestGenHTTPProxyInTunnel(t *testing.T) {
+	resStruct := testResponse(t)
+	if !HTTPProxyInTunnel(resStruct) {
+		t.Errorf("Expected %s to be reachable in the tunnel only", resStruct.Config.Services.HTTPProxy)
+	}
+	file, err := GenHTTPProxy(resStruct, "pac", "warp-http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(file.Body), `return "PROXY 172.16.0.1:2480";`) {
+		t.Errorf("Expected no DIRECT fallback of the proxy, but got:\n%s", file.Body)
+	}
+
+	resStruct.Config.Services.HTTPProxy = "203.0.113.1:2480"
+	if HTTPProxyInTunnel(resStruct) {
+		t.Errorf("Expected %s to be reachable outside of the tunnel", resStruct.Config.Services.HTTPProxy)
+	}
+}
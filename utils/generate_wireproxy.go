@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// WireproxyOptions describes the proxy and tunnel sections of a wireproxy config
+type WireproxyOptions struct {
+	Socks5     string
+	Socks5Auth string
+	HTTP       string
+	HTTPAuth   string
+	// Tunnels are port forwards in 'bind_address=target_address' format
+	Tunnels []string
+}
+
+func wireproxySection(name string, bindAddress string, auth string) (string, error) {
+	if _, _, err := net.SplitHostPort(bindAddress); err != nil {
+		return "", fmt.Errorf("invalid %s bind address: %w", name, err)
+	}
+	section := "\n[" + name + "]\nBindAddress = " + bindAddress + "\n"
+	if auth != "" {
+		user, pass, found := strings.Cut(auth, ":")
+		if !found {
+			return "", errors.New(name + " auth must be in 'user:pass' format")
+		}
+		section += "Username = " + user + "\nPassword = " + pass + "\n"
+	}
+	return section, nil
+}
+
+func GenWireproxy(resStruct C.Response, generateOptions C.GenerateOptions, options WireproxyOptions) (body []byte, err error) {
+	endpoint, err := joinAddress(resStruct, generateOptions)
+	if err != nil {
+		return
+	}
+	var dns string
+	if len(generateOptions.DNS) > 0 {
+		dns = "DNS = " + strings.Join(generateOptions.DNS, ", ") + "\n"
+	}
+
+	inStr := fmt.Sprint(`[Interface]
+PrivateKey = ` + resStruct.Config.PrivateKey + `
+` + wgLines("Address", interfaceAddresses(resStruct, generateOptions)) + dns + `MTU = ` + strconv.Itoa(generateOptions.MTU) + `
+
+[Peer]
+PublicKey = ` + resStruct.Config.Peers[0].PublicKey + `
+` + policyComments(resStruct, generateOptions) + `AllowedIPs = ` + strings.Join(generateOptions.AllowedIPs, ", ") + `
+Endpoint = ` + endpoint + `
+` + keepaliveLine("PersistentKeepalive", generateOptions))
+
+	var section string
+	if options.Socks5 != "" {
+		if section, err = wireproxySection("Socks5", options.Socks5, options.Socks5Auth); err != nil {
+			return
+		}
+		inStr += section
+	}
+	if options.HTTP != "" {
+		if section, err = wireproxySection("http", options.HTTP, options.HTTPAuth); err != nil {
+			return
+		}
+		inStr += section
+	}
+	for _, tunnel := range options.Tunnels {
+		bindAddress, target, found := strings.Cut(tunnel, "=")
+		if !found {
+			return nil, errors.New("tunnel must be in 'bind_address=target_address' format: " + tunnel)
+		}
+		if section, err = wireproxySection("TCPClientTunnel", bindAddress, ""); err != nil {
+			return
+		}
+		if _, _, err = net.SplitHostPort(target); err != nil {
+			return nil, fmt.Errorf("invalid tunnel target: %w", err)
+		}
+		inStr += section + "Target = " + target + "\n"
+	}
+
+	body = []byte(inStr)
+	return
+}
@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// XraySections lists the toggleable sections of a full xray config
+var XraySections = []string{"log", "inbound", "direct", "dns", "routing"}
+
+// XrayScaffold describes the parts of a full xray config around the wireguard outbound
+type XrayScaffold struct {
+	Sections []string
+	Inbound  string
+	Listen   string
+	Port     uint16
+	Auth     string
+}
+
+func GenXrayFull(resStruct C.Response, options C.GenerateOptions, tag string, indentSize uint8, scaffold XrayScaffold) (body []byte, err error) {
+	for _, section := range scaffold.Sections {
+		if !slices.Contains(XraySections, section) {
+			return nil, errors.New("unsupported xray section " + section)
+		}
+	}
+	enabled := func(section string) bool {
+		return slices.Contains(scaffold.Sections, section)
+	}
+
+	outbound, err := xrayOutbound(resStruct, options, tag)
+	if err != nil {
+		return
+	}
+	config := C.XrayConfig{
+		Outbounds: []any{outbound},
+	}
+
+	if enabled("log") {
+		config.Log = &struct {
+			LogLevel string `json:"loglevel"`
+		}{LogLevel: "warning"}
+	}
+
+	if enabled("inbound") {
+		inbound := C.XrayInbound{
+			Tag:      scaffold.Inbound + "-in",
+			Listen:   scaffold.Listen,
+			Port:     scaffold.Port,
+			Protocol: scaffold.Inbound,
+		}
+		switch scaffold.Inbound {
+		case "socks":
+			inbound.Settings.Auth = "noauth"
+			inbound.Settings.UDP = true
+		case "http":
+		default:
+			return nil, errors.New("unsupported xray inbound " + scaffold.Inbound)
+		}
+		if scaffold.Auth != "" {
+			user, pass, found := strings.Cut(scaffold.Auth, ":")
+			if !found {
+				return nil, errors.New("xray inbound auth must be in 'user:pass' format")
+			}
+			inbound.Settings.Accounts = []C.XrayAccount{{User: user, Pass: pass}}
+			if scaffold.Inbound == "socks" {
+				inbound.Settings.Auth = "password"
+			}
+		}
+		inbound.Sniffing.Enabled = true
+		inbound.Sniffing.DestOverride = []string{"http", "tls", "quic"}
+		config.Inbounds = []C.XrayInbound{inbound}
+	}
+
+	if enabled("direct") {
+		config.Outbounds = append(config.Outbounds, C.XrayOutbound{Protocol: "freedom", Tag: "direct"})
+	}
+
+	if enabled("dns") {
+		config.DNS = &C.XrayDNS{}
+		if doh, _, err := GatewayDNS(resStruct); options.GatewayDNS && err == nil {
+			config.DNS.Servers = append(config.DNS.Servers, doh)
+		}
+		for _, server := range options.DNS {
+			config.DNS.Servers = append(config.DNS.Servers, server)
+		}
+		if len(config.DNS.Servers) == 0 {
+			config.DNS.Servers = append(config.DNS.Servers, "localhost")
+		}
+		if host, _, _ := SelectEndpoint(resStruct, options); !isIPEndpoint(host) {
+			config.DNS.Servers = append([]any{C.XrayDNSServer{Address: "localhost", Domains: []string{"full:" + host}}}, config.DNS.Servers...)
+		}
+		if suffixes := fallbackDomains(resStruct); len(suffixes) > 0 {
+			fallback := C.XrayDNSServer{Address: Ternary(options.FallbackResolver == "local", "localhost", options.FallbackResolver)}
+			for _, suffix := range suffixes {
+				fallback.Domains = append(fallback.Domains, "domain:"+suffix)
+			}
+			config.DNS.Servers = append([]any{fallback}, config.DNS.Servers...)
+		}
+	}
+
+	if enabled("routing") {
+		if !enabled("direct") {
+			return nil, errors.New("xray routing section requires direct section")
+		}
+		config.Routing = &C.XrayRouting{
+			DomainStrategy: "IPIfNonMatch",
+			Rules: []C.XrayRoutingRule{
+				{Type: "field", IP: privateCIDRs, OutboundTag: "direct"},
+			},
+		}
+		if cidrs := policyDirectCIDRs(resStruct, options); len(cidrs) > 0 {
+			config.Routing.Rules = append(config.Routing.Rules, C.XrayRoutingRule{Type: "field", IP: cidrs, OutboundTag: "direct"})
+		}
+		if hosts := policyDirectDomains(resStruct, options); len(hosts) > 0 {
+			var domains []string
+			for _, host := range hosts {
+				domains = append(domains, "domain:"+host)
+			}
+			config.Routing.Rules = append(config.Routing.Rules, C.XrayRoutingRule{Type: "field", Domain: domains, OutboundTag: "direct"})
+		}
+	}
+
+	body, err = json.MarshalIndent(config, "", strings.Repeat(" ", int(indentSize)))
+	return
+}
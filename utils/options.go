@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// AddressFamilies lists the supported values of GenerateOptions.Family
+var AddressFamilies = []string{"both", "ipv4", "ipv6"}
+
+func DefaultGenerateOptions() C.GenerateOptions {
+	return C.GenerateOptions{
+		MTU:              1280,
+		Keepalive:        25,
+		AllowedIPs:       []string{"0.0.0.0/0", "::/0"},
+		Family:           "both",
+		FallbackResolver: "local",
+		Endpoint:         "domain",
+	}
+}
+
+// NormalizeOptions validates options, drops the DNS servers and AllowedIPs
+// which do not belong to the selected address family and removes the
+// excluded prefixes from AllowedIPs. The 'gateway' DNS server enables GatewayDNS
+func NormalizeOptions(options C.GenerateOptions) (C.GenerateOptions, error) {
+	switch options.Family {
+	case "both", "ipv4", "ipv6":
+	default:
+		return options, errors.New("unsupported address family " + options.Family)
+	}
+	if options.MTU < 576 || options.MTU > 65535 {
+		return options, errors.New("MTU must be between 576 and 65535")
+	}
+	if options.MTU < 1280 && hasIPv6(options) {
+		return options, errors.New("MTU must be at least 1280 when IPv6 is enabled")
+	}
+	if options.Keepalive < 0 || options.Keepalive > 65535 {
+		return options, errors.New("keepalive must be between 0 and 65535 seconds")
+	}
+	if _, err := ParseEndpointType(options.Endpoint); err != nil {
+		return options, err
+	}
+	// port 0 keeps the port of the selected endpoint
+	if options.Port < 0 || options.Port > 65535 {
+		return options, errors.New("port must be between 1 and 65535, or 0 for the endpoint default")
+	}
+
+	var dns []string
+	for _, server := range options.DNS {
+		if server == "gateway" {
+			options.GatewayDNS = true
+			continue
+		}
+		addr, err := netip.ParseAddr(server)
+		if err != nil {
+			return options, fmt.Errorf("invalid DNS server: %w", err)
+		}
+		if familyAllowed(options, addr.Is4()) {
+			dns = append(dns, addr.String())
+		}
+	}
+	if options.FallbackResolver != "local" {
+		addr, err := netip.ParseAddr(options.FallbackResolver)
+		if err != nil {
+			return options, fmt.Errorf("invalid fallback resolver: %w", err)
+		}
+		options.FallbackResolver = addr.String()
+	}
+
+	var include, exclude []netip.Prefix
+	for _, allowed := range options.AllowedIPs {
+		prefix, err := ParsePrefix(allowed)
+		if err != nil {
+			return options, fmt.Errorf("invalid AllowedIPs: %w", err)
+		}
+		if familyAllowed(options, prefix.Addr().Is4()) {
+			include = append(include, prefix)
+		}
+	}
+	for _, excluded := range options.Exclude {
+		entries := []string{excluded}
+		if excluded == "private" {
+			entries = privateCIDRs
+		}
+		for _, entry := range entries {
+			prefix, err := ParsePrefix(entry)
+			if err != nil {
+				return options, fmt.Errorf("invalid excluded prefix: %w", err)
+			}
+			exclude = append(exclude, prefix)
+		}
+	}
+	var allowedIPs []string
+	for _, prefix := range SubtractPrefixes(include, exclude) {
+		allowedIPs = append(allowedIPs, prefix.String())
+	}
+	if len(allowedIPs) == 0 {
+		return options, errors.New("no AllowedIPs left for address family " + options.Family + " after exclusions")
+	}
+	options.DNS = dns
+	options.AllowedIPs = allowedIPs
+	return options, nil
+}
+
+// fullTunnel reports whether AllowedIPs cover every address of the selected address families
+func fullTunnel(options C.GenerateOptions) bool {
+	var everything, allowed []netip.Prefix
+	if hasIPv4(options) {
+		everything = append(everything, netip.MustParsePrefix("0.0.0.0/0"))
+	}
+	if hasIPv6(options) {
+		everything = append(everything, netip.MustParsePrefix("::/0"))
+	}
+	for _, cidr := range options.AllowedIPs {
+		if prefix, err := ParsePrefix(cidr); err == nil {
+			allowed = append(allowed, prefix)
+		}
+	}
+	return len(SubtractPrefixes(everything, allowed)) == 0
+}
+
+func familyAllowed(options C.GenerateOptions, isIPv4 bool) bool {
+	return Ternary(isIPv4, hasIPv4(options), hasIPv6(options))
+}
+
+func hasIPv4(options C.GenerateOptions) bool {
+	return options.Family != "ipv6"
+}
+
+func hasIPv6(options C.GenerateOptions) bool {
+	return options.Family != "ipv4"
+}
+
+// interfaceAddresses returns the tunnel addresses with prefix length of the selected address families
+func interfaceAddresses(resStruct C.Response, options C.GenerateOptions) []string {
+	var addresses []string
+	if hasIPv4(options) {
+		addresses = append(addresses, resStruct.Config.Interface.Addresses.V4+"/32")
+	}
+	if hasIPv6(options) {
+		addresses = append(addresses, resStruct.Config.Interface.Addresses.V6+"/128")
+	}
+	return addresses
+}
+
+// dnsByFamily splits the DNS servers into IPv4 and IPv6 ones
+func dnsByFamily(options C.GenerateOptions) (v4 []string, v6 []string) {
+	for _, server := range options.DNS {
+		if strings.Contains(server, ":") {
+			v6 = append(v6, server)
+		} else {
+			v4 = append(v4, server)
+		}
+	}
+	return
+}
+
+// wgLines renders one 'key = value' line per value
+func wgLines(key string, values []string) string {
+	var lines string
+	for _, value := range values {
+		lines += key + " = " + value + "\n"
+	}
+	return lines
+}
+
+// keepaliveLine renders a 'key = value' line, or nothing when keepalive is disabled
+func keepaliveLine(key string, options C.GenerateOptions) string {
+	if options.Keepalive == 0 {
+		return ""
+	}
+	return key + " = " + strconv.Itoa(options.Keepalive) + "\n"
+}
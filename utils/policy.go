@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// PolicyExcludes returns the address split tunnel exclusions of the account Zero Trust policy
+func PolicyExcludes(resStruct C.Response) ([]string, error) {
+	if resStruct.Policy == nil {
+		return nil, errors.New("account has no Zero Trust policy")
+	}
+	var addresses []string
+	for _, exclude := range resStruct.Policy.Exclude {
+		if exclude.Address != "" {
+			addresses = append(addresses, exclude.Address)
+		}
+	}
+	return addresses, nil
+}
+
+// PolicyExcludeHosts returns the domain split tunnel exclusions of the account Zero Trust policy,
+// which cannot be expressed in AllowedIPs
+func PolicyExcludeHosts(resStruct C.Response) (hosts []string) {
+	if resStruct.Policy == nil {
+		return
+	}
+	for _, exclude := range resStruct.Policy.Exclude {
+		if host := strings.Trim(strings.TrimPrefix(exclude.Host, "*."), "."); exclude.Address == "" && host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return
+}
+
+// ApplyPolicyExclude removes the policy exclusions from AllowedIPs
+func ApplyPolicyExclude(resStruct C.Response, options C.GenerateOptions) (C.GenerateOptions, error) {
+	addresses, err := PolicyExcludes(resStruct)
+	if err != nil {
+		return options, err
+	}
+	options.Exclude = append(append([]string{}, options.Exclude...), addresses...)
+	return NormalizeOptions(options)
+}
+
+// policyDirectCIDRs returns the policy exclusions of the selected address families, to be routed directly
+func policyDirectCIDRs(resStruct C.Response, options C.GenerateOptions) (cidrs []string) {
+	if !options.ApplyPolicy || resStruct.Policy == nil {
+		return
+	}
+	for _, exclude := range resStruct.Policy.Exclude {
+		prefix, err := ParsePrefix(exclude.Address)
+		if err == nil && familyAllowed(options, prefix.Addr().Is4()) {
+			cidrs = append(cidrs, prefix.String())
+		}
+	}
+	return
+}
+
+// policyDirectDomains returns the domain policy exclusions, to be routed directly
+func policyDirectDomains(resStruct C.Response, options C.GenerateOptions) []string {
+	if !options.ApplyPolicy {
+		return nil
+	}
+	return PolicyExcludeHosts(resStruct)
+}
+
+// policyComments renders the policy exclusions with their descriptions as '#' comment lines
+func policyComments(resStruct C.Response, options C.GenerateOptions) string {
+	if !options.ApplyPolicy || resStruct.Policy == nil {
+		return ""
+	}
+	var lines string
+	for _, exclude := range resStruct.Policy.Exclude {
+		if exclude.Address != "" {
+			lines += "# Excluded by Zero Trust policy: " + exclude.Address
+		} else {
+			lines += "# Excluded by Zero Trust policy, not applicable to AllowedIPs: " + exclude.Host
+		}
+		if exclude.Description != "" {
+			lines += " (" + exclude.Description + ")"
+		}
+		lines += "\n"
+	}
+	return lines
+}
+
+// fallbackDomains returns the domain suffixes which the account Zero Trust policy resolves locally
+func fallbackDomains(resStruct C.Response) (suffixes []string) {
+	if resStruct.Policy == nil {
+		return
+	}
+	for _, domain := range resStruct.Policy.FallbackDomains {
+		if suffix := strings.Trim(domain.Suffix, "."); suffix != "" {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+	return
+}
+
+// fallbackComments renders the fallback domains as '#' comment lines
+func fallbackComments(resStruct C.Response, options C.GenerateOptions) string {
+	suffixes := fallbackDomains(resStruct)
+	if len(suffixes) == 0 {
+		return ""
+	}
+	lines := "# Zero Trust fallback domains, resolve them with the " + Ternary(options.FallbackResolver == "local", "local resolver", "resolver "+options.FallbackResolver) + " instead of the tunnel DNS:\n"
+	for _, suffix := range suffixes {
+		lines += "#   " + suffix + "\n"
+	}
+	return lines
+}
+
+// GatewayDNS returns the DNS over HTTPS URL and DNS over TLS host of the
+// Cloudflare Gateway DNS location identified by the account Zero Trust policy
+func GatewayDNS(resStruct C.Response) (doh string, dot string, err error) {
+	if resStruct.Policy == nil || resStruct.Policy.GatewayUniqueID == "" {
+		return "", "", errors.New("account has no Cloudflare Gateway DNS location")
+	}
+	dot = resStruct.Policy.GatewayUniqueID + ".cloudflare-gateway.com"
+	doh = "https://" + dot + "/dns-query"
+	return
+}
+
+// gatewayComments renders the Gateway DNS endpoints as '#' comment lines
+func gatewayComments(resStruct C.Response, options C.GenerateOptions) string {
+	if !options.GatewayDNS {
+		return ""
+	}
+	doh, dot, err := GatewayDNS(resStruct)
+	if err != nil {
+		return ""
+	}
+	return `# Cloudflare Gateway DNS, configure a local DoH/DoT client with these endpoints:
+#   DoH: ` + doh + `
+#   DoT: ` + dot + `
+`
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+func TestApplyPolicyExclude(t *testing.T) {
+	resStruct := testResponse(t)
+	addresses, err := PolicyExcludes(resStruct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(addresses, []string{"10.0.0.0/8", "192.168.0.0/16", "fd00::/8"}) {
+		t.Errorf("Expected the domain exclusion to be skipped, but got: %v", addresses)
+	}
+	if hosts := PolicyExcludeHosts(resStruct); !slices.Equal(hosts, []string{"intranet.example.com"}) {
+		t.Errorf("Expected the domain exclusion, but got: %v", hosts)
+	}
+
+	options, err := ApplyPolicyExclude(resStruct, testOptions(t, func(options *C.GenerateOptions) { options.ApplyPolicy = true }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(options.AllowedIPs, "0.0.0.0/0") || !slices.Contains(options.AllowedIPs, "11.0.0.0/8") {
+		t.Errorf("Expected the policy exclusions to be removed from AllowedIPs, but got: %v", options.AllowedIPs)
+	}
+	if comments := policyComments(resStruct, options); !strings.Contains(comments, "not applicable to AllowedIPs: intranet.example.com (intranet)") {
+		t.Errorf("Expected a comment for the domain exclusion, but got:\n%s", comments)
+	}
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// addrRange is an inclusive range of addresses of one family
+type addrRange struct {
+	from, to netip.Addr
+}
+
+// ParsePrefix parses a CIDR, a bare address is treated as a single host prefix
+func ParsePrefix(value string) (netip.Prefix, error) {
+	if !strings.Contains(value, "/") {
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(value)
+	if err != nil {
+		return prefix, err
+	}
+	if prefix.Addr().Is4In6() {
+		if prefix.Bits() < 96 {
+			return netip.Prefix{}, fmt.Errorf("IPv4-mapped prefix %s must be at least /96", value)
+		}
+		prefix = netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()-96)
+	}
+	return prefix.Masked(), nil
+}
+
+// ReadPrefixList reads a list file of one CIDR per line, blank lines and '#' comments are ignored
+func ReadPrefixList(filePath string) (prefixes []string, err error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry, _, _ := strings.Cut(scanner.Text(), "#")
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, err = ParsePrefix(entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filePath, line, err)
+		}
+		prefixes = append(prefixes, entry)
+	}
+	err = scanner.Err()
+	return
+}
+
+// SubtractPrefixes returns the minimal set of prefixes covering include but none of exclude
+func SubtractPrefixes(include []netip.Prefix, exclude []netip.Prefix) []netip.Prefix {
+	remaining := mergeRanges(include)
+	for _, excluded := range mergeRanges(exclude) {
+		var next []addrRange
+		for _, r := range remaining {
+			if excluded.to.Less(r.from) || r.to.Less(excluded.from) {
+				next = append(next, r)
+				continue
+			}
+			if r.from.Less(excluded.from) {
+				next = append(next, addrRange{r.from, excluded.from.Prev()})
+			}
+			if excluded.to.Less(r.to) {
+				next = append(next, addrRange{excluded.to.Next(), r.to})
+			}
+		}
+		remaining = next
+	}
+
+	var prefixes []netip.Prefix
+	for _, r := range remaining {
+		prefixes = append(prefixes, rangePrefixes(r)...)
+	}
+	return prefixes
+}
+
+// mergeRanges converts prefixes into sorted ranges, joining overlapping and adjacent ones,
+// invalid prefixes are skipped
+func mergeRanges(prefixes []netip.Prefix) []addrRange {
+	var ranges []addrRange
+	for _, prefix := range prefixes {
+		if !prefix.IsValid() {
+			continue
+		}
+		prefix = prefix.Masked()
+		ranges = append(ranges, addrRange{prefix.Addr(), lastAddr(prefix)})
+	}
+	slices.SortFunc(ranges, func(a, b addrRange) int {
+		return a.from.Compare(b.from)
+	})
+
+	var merged []addrRange
+	for _, r := range ranges {
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			next := last.to.Next()
+			if last.to.BitLen() == r.from.BitLen() && (!next.IsValid() || !next.Less(r.from)) {
+				if last.to.Less(r.to) {
+					last.to = r.to
+				}
+				continue
+			}
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// rangePrefixes splits a range into the fewest prefixes covering it exactly
+func rangePrefixes(r addrRange) (prefixes []netip.Prefix) {
+	for current := r.from; current.IsValid() && !r.to.Less(current); {
+		best := netip.PrefixFrom(current, current.BitLen())
+		for bits := current.BitLen() - 1; bits >= 0; bits-- {
+			prefix := netip.PrefixFrom(current, bits)
+			if prefix.Masked().Addr() != current || r.to.Less(lastAddr(prefix)) {
+				break
+			}
+			best = prefix
+		}
+		prefixes = append(prefixes, best)
+		current = lastAddr(best).Next()
+	}
+	return
+}
+
+// lastAddr returns the highest address inside prefix, or the zero Addr for an invalid prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	if !prefix.IsValid() {
+		return netip.Addr{}
+	}
+	bytes := prefix.Addr().As16()
+	offset := 16 - prefix.Addr().BitLen()/8
+	for bit := prefix.Bits(); bit < prefix.Addr().BitLen(); bit++ {
+		bytes[offset+bit/8] |= 0x80 >> (bit % 8)
+	}
+	if prefix.Addr().Is4() {
+		return netip.AddrFrom4([4]byte(bytes[12:]))
+	}
+	return netip.AddrFrom16(bytes)
+}
@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"10.0.0.0/8", "10.0.0.0/8", false},
+		{"10.1.2.3/8", "10.0.0.0/8", false},
+		{"1.1.1.1", "1.1.1.1/32", false},
+		{"2606:4700::1111", "2606:4700::1111/128", false},
+		{"0.0.0.0/0", "0.0.0.0/0", false},
+		{"::/0", "::/0", false},
+		{"::ffff:10.0.0.0/104", "10.0.0.0/8", false},
+		{"::ffff:1.1.1.1", "1.1.1.1/32", false},
+		{"::ffff:0.0.0.0/80", "", true},
+		{"10.0.0.0/33", "", true},
+		{"example.com", "", true},
+		{"", "", true},
+	}
+	for _, test := range tests {
+		prefix, err := ParsePrefix(test.value)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("ParsePrefix(%q) = %s, expected an error", test.value, prefix)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParsePrefix(%q) returned error: %v", test.value, err)
+			continue
+		}
+		if prefix.String() != test.want {
+			t.Errorf("ParsePrefix(%q) = %s, expected %s", test.value, prefix, test.want)
+		}
+	}
+}
+
+func TestSubtractPrefixes(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		want    []string
+	}{
+		{
+			name:    "nothing excluded",
+			include: []string{"0.0.0.0/0", "::/0"},
+			want:    []string{"0.0.0.0/0", "::/0"},
+		},
+		{
+			name:    "everything excluded",
+			include: []string{"0.0.0.0/0"},
+			exclude: []string{"0.0.0.0/0"},
+		},
+		{
+			name:    "lower half excluded",
+			include: []string{"0.0.0.0/0"},
+			exclude: []string{"0.0.0.0/1"},
+			want:    []string{"128.0.0.0/1"},
+		},
+		{
+			name:    "single host excluded",
+			include: []string{"10.0.0.0/30"},
+			exclude: []string{"10.0.0.1/32"},
+			want:    []string{"10.0.0.0/32", "10.0.0.2/31"},
+		},
+		{
+			name:    "last host excluded",
+			include: []string{"255.255.255.0/24"},
+			exclude: []string{"255.255.255.255/32"},
+			want:    []string{"255.255.255.0/25", "255.255.255.128/26", "255.255.255.192/27", "255.255.255.224/28", "255.255.255.240/29", "255.255.255.248/30", "255.255.255.252/31", "255.255.255.254/32"},
+		},
+		{
+			name:    "adjacent prefixes merged",
+			include: []string{"10.0.0.0/9", "10.128.0.0/9", "11.0.0.0/8"},
+			want:    []string{"10.0.0.0/7"},
+		},
+		{
+			name:    "overlapping prefixes merged",
+			include: []string{"10.0.0.0/8", "10.1.0.0/16", "10.0.0.0/9"},
+			want:    []string{"10.0.0.0/8"},
+		},
+		{
+			name:    "adjacent excludes merged",
+			include: []string{"10.0.0.0/8"},
+			exclude: []string{"10.0.0.0/9", "10.128.0.0/9"},
+		},
+		{
+			name:    "families kept apart",
+			include: []string{"0.0.0.0/0", "::/0"},
+			exclude: []string{"::/1"},
+			want:    []string{"0.0.0.0/0", "8000::/1"},
+		},
+		{
+			name:    "excludes of the other family ignored",
+			include: []string{"10.0.0.0/8"},
+			exclude: []string{"::/0"},
+			want:    []string{"10.0.0.0/8"},
+		},
+		{
+			name:    "complement of private IPv4 ranges",
+			include: []string{"0.0.0.0/0"},
+			exclude: []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+			want: []string{
+				"0.0.0.0/5", "8.0.0.0/7", "11.0.0.0/8", "12.0.0.0/6", "16.0.0.0/4", "32.0.0.0/3", "64.0.0.0/2",
+				"128.0.0.0/3", "160.0.0.0/5", "168.0.0.0/6", "172.0.0.0/12", "172.32.0.0/11", "172.64.0.0/10",
+				"172.128.0.0/9", "173.0.0.0/8", "174.0.0.0/7", "176.0.0.0/4", "192.0.0.0/9", "192.128.0.0/11",
+				"192.160.0.0/13", "192.169.0.0/16", "192.170.0.0/15", "192.172.0.0/14", "192.176.0.0/12",
+				"192.192.0.0/10", "193.0.0.0/8", "194.0.0.0/7", "196.0.0.0/6", "200.0.0.0/5", "208.0.0.0/4",
+				"224.0.0.0/3",
+			},
+		},
+		{
+			name:    "complement of private IPv6 ranges",
+			include: []string{"::/0"},
+			exclude: []string{"fc00::/7", "fe80::/10"},
+			want: []string{
+				"::/1", "8000::/2", "c000::/3", "e000::/4", "f000::/5", "f800::/6", "fe00::/9", "fec0::/10", "ff00::/8",
+			},
+		},
+	}
+	for _, test := range tests {
+		var got []string
+		for _, prefix := range SubtractPrefixes(mustPrefixes(t, test.include), mustPrefixes(t, test.exclude)) {
+			got = append(got, prefix.String())
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: SubtractPrefixes(%v, %v) = %v, expected %v", test.name, test.include, test.exclude, got, test.want)
+		}
+	}
+}
+
+func TestSubtractPrefixesInvalid(t *testing.T) {
+	include := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), {}}
+	exclude := []netip.Prefix{{}, netip.PrefixFrom(netip.MustParseAddr("10.0.0.0"), -1)}
+	got := SubtractPrefixes(include, exclude)
+	if len(got) != 1 || got[0].String() != "10.0.0.0/8" {
+		t.Errorf("SubtractPrefixes with invalid prefixes = %v, expected [10.0.0.0/8]", got)
+	}
+}
+
+func mustPrefixes(t *testing.T, values []string) (prefixes []netip.Prefix) {
+	for _, value := range values {
+		prefix, err := ParsePrefix(value)
+		if err != nil {
+			t.Fatalf("ParsePrefix(%q) returned error: %v", value, err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return
+}
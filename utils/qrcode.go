@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+func newQRCode(payload string) (*qrcode.QRCode, error) {
+	qr, err := qrcode.New(payload, qrcode.Medium)
+	if err != nil {
+		return nil, fmt.Errorf("payload of %d bytes does not fit into a QR code (at most 2331 bytes), use a shorter output such as --wg-quick or --share-link: %w", len(payload), err)
+	}
+	return qr, nil
+}
+
+// RenderQRTerminal renders payload as a QR code using Unicode half blocks.
+// Light modules are drawn as blocks which suits dark terminals, invert swaps it for light ones
+func RenderQRTerminal(payload string, invert bool) (string, error) {
+	qr, err := newQRCode(payload)
+	if err != nil {
+		return "", err
+	}
+	return qr.ToSmallString(invert), nil
+}
+
+func qrSVG(qr *qrcode.QRCode) []byte {
+	const scale int = 8
+	bitmap := qr.Bitmap()
+	size := strconv.Itoa(len(bitmap) * scale)
+
+	var builder strings.Builder
+	builder.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="` + size + `" height="` + size + `" viewBox="0 0 ` + strconv.Itoa(len(bitmap)) + ` ` + strconv.Itoa(len(bitmap)) + `" shape-rendering="crispEdges">
+<rect width="100%" height="100%" fill="#ffffff"/>
+<path fill="#000000" d="`)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				builder.WriteString("M" + strconv.Itoa(x) + " " + strconv.Itoa(y) + "h1v1h-1z")
+			}
+		}
+	}
+	builder.WriteString(`"/>
+</svg>
+`)
+	return []byte(builder.String())
+}
+
+// WriteQRFile writes payload as a QR code image, the format is chosen by the '.png' or '.svg' extension
+func WriteQRFile(payload string, filePath string) error {
+	qr, err := newQRCode(payload)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	switch strings.ToLower(path.Ext(filePath)) {
+	case ".png":
+		if body, err = qr.PNG(-8); err != nil {
+			return err
+		}
+	case ".svg":
+		body = qrSVG(qr)
+	default:
+		return errors.New("unsupported QR code image format, use a '.png' or '.svg' file")
+	}
+	return os.WriteFile(filePath, body, 0600)
+}
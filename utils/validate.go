@@ -0,0 +1,363 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	E "github.com/ArchiveNetwork/wgcf-cli/enum"
+)
+
+//go:embed schemas/*.json
+var schemas embed.FS
+
+// ValidationError lists everything wrong with a generated config
+type ValidationError struct {
+	Problems []string
+}
+
+func (err *ValidationError) Error() string {
+	return "generated config is invalid:\n  - " + strings.Join(err.Problems, "\n  - ")
+}
+
+// jsonSchema is the subset of JSON Schema used by the embedded schemas
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+	Enum       []any                  `json:"enum"`
+	Format     string                 `json:"format"`
+	Minimum    *float64               `json:"minimum"`
+	Maximum    *float64               `json:"maximum"`
+	MinItems   *int                   `json:"minItems"`
+	MaxItems   *int                   `json:"maxItems"`
+	MinLength  *int                   `json:"minLength"`
+}
+
+var wgQuickKeys = map[string][]string{
+	"Interface": {"PrivateKey", "Address", "DNS", "MTU", "Table", "PreUp", "PostUp", "PreDown", "PostDown", "SaveConfig", "ListenPort", "FwMark"},
+	"Peer":      {"PublicKey", "PresharedKey", "AllowedIPs", "Endpoint", "PersistentKeepalive"},
+}
+
+var wgSetconfKeys = map[string][]string{
+	"Interface": {"PrivateKey", "ListenPort", "FwMark"},
+	"Peer":      wgQuickKeys["Peer"],
+}
+
+var amneziaWGKeys = map[string][]string{
+	"Interface": append(slices.Clone(wgQuickKeys["Interface"]), "Jc", "Jmin", "Jmax", "S1", "S2", "H1", "H2", "H3", "H4"),
+	"Peer":      wgQuickKeys["Peer"],
+}
+
+// repeatableKeys may appear several times in a section
+var repeatableKeys = []string{"Address", "DNS", "AllowedIPs", "PreUp", "PostUp", "PreDown", "PostDown"}
+
+// Validate checks that a generated file is accepted by the target program,
+// generators without checks always pass
+func Validate(generator E.GeneratorType, file GeneratedFile) error {
+	var problems []string
+	switch generator {
+	case E.Xray:
+		problems = validateJSON(file.Body, "xray-wireguard", xrayWireguards)
+	case E.SingBox:
+		problems = validateJSON(file.Body, "", singWireguards)
+	case E.WgQuick:
+		problems = validateWgConfig(file.Body, wgQuickKeys)
+	case E.WgSetconf:
+		problems = validateWgConfig(file.Body, wgSetconfKeys)
+	case E.AmneziaWG:
+		problems = validateWgConfig(file.Body, amneziaWGKeys)
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func loadSchema(name string) (*jsonSchema, error) {
+	body, err := schemas.ReadFile("schemas/" + name + ".json")
+	if err != nil {
+		return nil, err
+	}
+	var schema jsonSchema
+	err = json.Unmarshal(body, &schema)
+	return &schema, err
+}
+
+// wireguardObject is a wireguard object found in a config with the schema validating it
+type wireguardObject struct {
+	path   string
+	schema string
+	value  map[string]any
+}
+
+// xrayWireguards finds the wireguard outbounds of a single outbound, a module or a full config
+func xrayWireguards(config map[string]any, schema string) (objects []wireguardObject) {
+	if _, ok := config["protocol"]; ok {
+		return []wireguardObject{{"$", schema, config}}
+	}
+	for key, value := range config {
+		list, _ := value.([]any)
+		for i, item := range list {
+			if object, ok := item.(map[string]any); ok && object["protocol"] == "wireguard" {
+				objects = append(objects, wireguardObject{"$." + key + "[" + strconv.Itoa(i) + "]", schema, object})
+			}
+		}
+	}
+	return
+}
+
+// singWireguards finds the wireguard endpoints and legacy outbounds of a single object or a profile
+func singWireguards(config map[string]any, _ string) (objects []wireguardObject) {
+	schemaOf := func(object map[string]any) string {
+		if object["local_address"] == nil {
+			return "sing-box-endpoint"
+		}
+		return Ternary(object["peers"] != nil, "sing-box-outbound-peers", "sing-box-outbound")
+	}
+	if _, ok := config["type"]; ok {
+		return []wireguardObject{{"$", schemaOf(config), config}}
+	}
+	for _, key := range []string{"endpoints", "outbounds"} {
+		list, _ := config[key].([]any)
+		for i, item := range list {
+			if object, ok := item.(map[string]any); ok && object["type"] == "wireguard" {
+				objects = append(objects, wireguardObject{"$." + key + "[" + strconv.Itoa(i) + "]", schemaOf(object), object})
+			}
+		}
+	}
+	return
+}
+
+func validateJSON(body []byte, schema string, find func(map[string]any, string) []wireguardObject) (problems []string) {
+	var config map[string]any
+	if err := json.Unmarshal(body, &config); err != nil {
+		return []string{"invalid JSON: " + err.Error()}
+	}
+	objects := find(config, schema)
+	if len(objects) == 0 {
+		return []string{"no wireguard outbound or endpoint found"}
+	}
+	for _, object := range objects {
+		schema, err := loadSchema(object.schema)
+		if err != nil {
+			return []string{"loading schema " + object.schema + ": " + err.Error()}
+		}
+		problems = append(problems, schema.validate(object.path, object.value)...)
+	}
+	return
+}
+
+func (schema *jsonSchema) validate(path string, value any) (problems []string) {
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+		return []string{fmt.Sprintf("%s: must be one of %v, got %v", path, schema.Enum, value)}
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []string{path + ": must be an object"}
+		}
+		for _, key := range schema.Required {
+			if _, ok := object[key]; !ok {
+				problems = append(problems, path+"."+key+": is required")
+			}
+		}
+		var keys []string
+		for key := range schema.Properties {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if field, ok := object[key]; ok {
+				problems = append(problems, schema.Properties[key].validate(path+"."+key, field)...)
+			}
+		}
+	case "array":
+		list, ok := value.([]any)
+		if !ok {
+			return []string{path + ": must be an array"}
+		}
+		if schema.MinItems != nil && len(list) < *schema.MinItems {
+			problems = append(problems, fmt.Sprintf("%s: must have at least %d items", path, *schema.MinItems))
+		}
+		if schema.MaxItems != nil && len(list) > *schema.MaxItems {
+			problems = append(problems, fmt.Sprintf("%s: must have at most %d items", path, *schema.MaxItems))
+		}
+		if schema.Items != nil {
+			for i, item := range list {
+				problems = append(problems, schema.Items.validate(path+"["+strconv.Itoa(i)+"]", item)...)
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []string{path + ": must be a string"}
+		}
+		if schema.MinLength != nil && len(str) < *schema.MinLength {
+			problems = append(problems, path+": must not be empty")
+		}
+		if err := checkFormat(schema.Format, str); err != nil {
+			problems = append(problems, path+": "+err.Error())
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return []string{path + ": must be an integer"}
+		}
+		if schema.Minimum != nil && number < *schema.Minimum || schema.Maximum != nil && number > *schema.Maximum {
+			problems = append(problems, fmt.Sprintf("%s: %v is out of range", path, number))
+		}
+	}
+	return
+}
+
+func checkFormat(format string, value string) error {
+	switch format {
+	case "wg-key":
+		key, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("%q is not a base64 encoded 32 byte key", value)
+		}
+	case "cidr":
+		if _, err := ParsePrefix(value); err != nil {
+			return fmt.Errorf("%q is not an address or CIDR", value)
+		}
+	case "host-port":
+		host, port, err := net.SplitHostPort(value)
+		if err != nil {
+			return fmt.Errorf("%q is not in 'host:port' format", value)
+		}
+		if parsed, err := strconv.Atoi(port); host == "" || err != nil || parsed < 1 || parsed > 65535 {
+			return fmt.Errorf("%q has no valid host or port", value)
+		}
+	}
+	return nil
+}
+
+// validateWgConfig parses a WireGuard INI config and checks its sections, keys and values
+func validateWgConfig(body []byte, keys map[string][]string) (problems []string) {
+	var section string
+	var interfaces, peers int
+	var seen map[string]bool
+	requireKeys := func() {
+		switch section {
+		case "Interface":
+			if !seen["PrivateKey"] {
+				problems = append(problems, "[Interface]: PrivateKey is required")
+			}
+		case "Peer":
+			if !seen["PublicKey"] {
+				problems = append(problems, "[Peer]: PublicKey is required")
+			}
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		location := "line " + strconv.Itoa(line)
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			requireKeys()
+			section = strings.Trim(text, "[]")
+			seen = map[string]bool{}
+			switch section {
+			case "Interface":
+				interfaces++
+			case "Peer":
+				peers++
+			default:
+				problems = append(problems, location+": unknown section "+text)
+			}
+			continue
+		}
+		key, value, found := strings.Cut(text, "=")
+		if !found {
+			problems = append(problems, location+": expected 'key = value'")
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if section == "" {
+			problems = append(problems, location+": "+key+" is outside of a section")
+			continue
+		}
+		if !slices.Contains(keys[section], key) {
+			problems = append(problems, location+": unknown key "+key+" in ["+section+"]")
+			continue
+		}
+		if seen[key] && !slices.Contains(repeatableKeys, key) {
+			problems = append(problems, location+": duplicate key "+key)
+		}
+		seen[key] = true
+		if err := checkWgValue(key, value); err != nil {
+			problems = append(problems, location+": "+key+": "+err.Error())
+		}
+	}
+	requireKeys()
+
+	if interfaces != 1 {
+		problems = append(problems, "exactly one [Interface] section is required")
+	}
+	if peers == 0 {
+		problems = append(problems, "at least one [Peer] section is required")
+	}
+	return
+}
+
+func checkWgValue(key string, value string) error {
+	switch key {
+	case "PrivateKey", "PublicKey", "PresharedKey":
+		return checkFormat("wg-key", value)
+	case "Address", "AllowedIPs":
+		for _, item := range strings.Split(value, ",") {
+			if err := checkFormat("cidr", strings.TrimSpace(item)); err != nil {
+				return err
+			}
+		}
+	case "Endpoint":
+		return checkFormat("host-port", value)
+	case "MTU":
+		return checkRange(value, 576, 65535)
+	case "ListenPort", "PersistentKeepalive":
+		if key == "PersistentKeepalive" && value == "off" {
+			return nil
+		}
+		return checkRange(value, 0, 65535)
+	case "Table":
+		if value == "off" || value == "auto" {
+			return nil
+		}
+		return checkRange(value, 0, math.MaxUint32)
+	case "FwMark":
+		if value == "off" {
+			return nil
+		}
+		if _, err := strconv.ParseUint(value, 0, 32); err != nil {
+			return fmt.Errorf("%q is not a firewall mark", value)
+		}
+	case "Jc", "Jmin", "Jmax", "S1", "S2", "H1", "H2", "H3", "H4":
+		return checkRange(value, 0, math.MaxUint32)
+	}
+	return nil
+}
+
+func checkRange(value string, min int64, max int64) error {
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || number < min || number > max {
+		return fmt.Errorf("%q must be an integer between %d and %d", value, min, max)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	E "github.com/ArchiveNetwork/wgcf-cli/enum"
+)
+
+const testKey = "SHVqHEGI7k2+OQ/oWMmWY2EQObbRQjRBdDPimh0h1WY="
+
+func TestValidateProblems(t *testing.T) {
+	tests := []struct {
+		name      string
+		generator E.GeneratorType
+		body      string
+		want      []string
+	}{
+		{
+			name:      "xray missing port",
+			generator: E.Xray,
+			body:      `{"protocol": "wireguard", "settings": {"secretKey": "` + testKey + `", "address": ["172.16.0.2/32"], "peers": [{"publicKey": "` + testKey + `", "endpoint": "engage.cloudflareclient.com"}]}}`,
+			want:      []string{`$.settings.peers[0].endpoint: "engage.cloudflareclient.com" is not in 'host:port' format`},
+		},
+		{
+			name:      "xray module wrapper",
+			generator: E.Xray,
+			body:      `{"outbounds": [{"protocol": "freedom"}, {"protocol": "wireguard", "settings": {"secretKey": "bad", "address": [], "peers": [{"publicKey": "` + testKey + `", "endpoint": "1.2.3.4:0"}]}}]}`,
+			want: []string{
+				`$.outbounds[1].settings.address: must have at least 1 items`,
+				`$.outbounds[1].settings.peers[0].endpoint: "1.2.3.4:0" has no valid host or port`,
+				`$.outbounds[1].settings.secretKey: "bad" is not a base64 encoded 32 byte key`,
+			},
+		},
+		{
+			name:      "xray without wireguard",
+			generator: E.Xray,
+			body:      `{"outbounds": [{"protocol": "freedom"}]}`,
+			want:      []string{"no wireguard outbound or endpoint found"},
+		},
+		{
+			name:      "sing-box endpoint",
+			generator: E.SingBox,
+			body:      `{"type": "wireguard", "tag": "warp", "address": ["172.16.0.2/32"], "private_key": "` + testKey + `", "peers": [{"address": "1.2.3.4", "port": 70000, "public_key": "` + testKey + `", "allowed_ips": ["0.0.0.0/0"]}]}`,
+			want:      []string{`$.peers[0].port: 70000 is out of range`},
+		},
+		{
+			name:      "sing-box legacy outbound",
+			generator: E.SingBox,
+			body:      `{"outbounds": [{"type": "wireguard", "tag": "warp", "server": "1.2.3.4", "local_address": ["172.16.0.2/32"], "private_key": "` + testKey + `", "peer_public_key": "` + testKey + `"}]}`,
+			want:      []string{`$.outbounds[0].server_port: is required`},
+		},
+		{
+			name:      "sing-box legacy outbound with peers",
+			generator: E.SingBox,
+			body:      `{"type": "wireguard", "tag": "warp", "local_address": ["172.16.0.2/32"], "private_key": "` + testKey + `", "peers": [{"server": "1.2.3.4", "server_port": 2408, "public_key": "` + testKey + `"}]}`,
+			want:      []string{`$.peers[0].allowed_ips: is required`},
+		},
+		{
+			name:      "wg-quick bad key",
+			generator: E.WgQuick,
+			body:      "[Interface]\nPrivateKey = short\n\n[Peer]\nPublicKey = " + testKey + "\nEndpoint = 1.2.3.4:2408\n",
+			want:      []string{`line 2: PrivateKey: "short" is not a base64 encoded 32 byte key`},
+		},
+		{
+			name:      "wg-quick unknown and duplicate keys",
+			generator: E.WgQuick,
+			body:      "[Interface]\nPrivateKey = " + testKey + "\nMTU = 1280\nMTU = 1420\nJc = 4\n\n[Peer]\nPublicKey = " + testKey + "\nAllowedIPs = 0.0.0.0/0\nAllowedIPs = ::/0\n",
+			want:      []string{"line 4: duplicate key MTU", "line 5: unknown key Jc in [Interface]"},
+		},
+		{
+			name:      "wg setconf without peer",
+			generator: E.WgSetconf,
+			body:      "[Interface]\nPrivateKey = " + testKey + "\nAddress = 172.16.0.2/32\n",
+			want:      []string{"line 3: unknown key Address in [Interface]", "at least one [Peer] section is required"},
+		},
+		{
+			name:      "amneziawg missing public key",
+			generator: E.AmneziaWG,
+			body:      "[Interface]\nPrivateKey = " + testKey + "\nJc = -1\n\n[Peer]\nEndpoint = 1.2.3.4:2408\n",
+			want:      []string{`line 3: Jc: "-1" must be an integer between 0 and 4294967295`, "[Peer]: PublicKey is required"},
+		},
+	}
+	for _, test := range tests {
+		err := Validate(test.generator, GeneratedFile{Body: []byte(test.body)})
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected a ValidationError, but got: %v", test.name, err)
+			continue
+		}
+		if !slices.Equal(validationErr.Problems, test.want) {
+			t.Errorf("%s: expected problems %q, but got %q", test.name, test.want, validationErr.Problems)
+		}
+	}
+}
+
+func TestValidateGenerated(t *testing.T) {
+	resStruct := testResponse(t)
+	options := testOptions(t, nil)
+	generate := map[E.GeneratorType]func() ([]byte, error){
+		E.Xray: func() ([]byte, error) { return GenXray(resStruct, options, "warp", "outbounds", 2) },
+		E.SingBox: func() ([]byte, error) {
+			return GenSing(resStruct, options, "warp", "1.11", true, "mixed")
+		},
+		E.WgQuick:   func() ([]byte, error) { return GenWgQuick(resStruct, options, DefaultWgQuickRouting()) },
+		E.WgSetconf: func() ([]byte, error) { return GenWgSetconf(resStruct, options) },
+	}
+	for generator, gen := range generate {
+		body, err := gen()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = Validate(generator, GeneratedFile{Body: body}); err != nil {
+			t.Errorf("%s: %v", generator, err)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package utils
+
+import (
+	"errors"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	C "github.com/ArchiveNetwork/wgcf-cli/constant"
+)
+
+// WgQuickRoutingModes lists the supported routing modes of wg-quick configs
+var WgQuickRoutingModes = []string{"fwmark", "policy", "full", "ipv4", "ipv6"}
+
+// WgQuickFirewalls lists the supported backends of the wg-quick marking rules
+var WgQuickFirewalls = []string{"iptables", "nftables"}
+
+// WgQuickRouting describes how a wg-quick config routes traffic into the tunnel
+type WgQuickRouting struct {
+	// Mode is one of WgQuickRoutingModes:
+	// fwmark routes packets from the tunnel address by marking them,
+	// policy routes packets from the tunnel address with source rules,
+	// full lets wg-quick route everything and ipv4/ipv6 do so for a single family
+	Mode     string
+	Table    uint32
+	FwMark   uint32
+	Firewall string
+	// KillSwitch rejects egress outside of the tunnel except to the endpoint
+	// and the destinations which are not routed into the tunnel
+	KillSwitch bool
+}
+
+func DefaultWgQuickRouting() WgQuickRouting {
+	return WgQuickRouting{
+		Mode:     "fwmark",
+		Table:    uint32(routeTable),
+		FwMark:   uint32(firewallMark),
+		Firewall: "iptables",
+	}
+}
+
+// Normalize validates routing and restricts options to the family of the ipv4/ipv6 modes
+func (routing WgQuickRouting) Normalize(options C.GenerateOptions) (C.GenerateOptions, error) {
+	if !slices.Contains(WgQuickRoutingModes, routing.Mode) {
+		return options, errors.New("unsupported wg-quick routing mode " + routing.Mode)
+	}
+	if !slices.Contains(WgQuickFirewalls, routing.Firewall) {
+		return options, errors.New("unsupported wg-quick firewall " + routing.Firewall)
+	}
+	if routing.Table == 0 || routing.Table >= 253 && routing.Table <= 255 {
+		return options, errors.New("wg-quick routing table must not be 0 or a reserved table (253-255)")
+	}
+	if routing.FwMark == 0 {
+		return options, errors.New("wg-quick firewall mark must not be 0")
+	}
+	if routing.KillSwitch && (routing.Mode == "fwmark" || routing.Mode == "policy") {
+		return options, errors.New("kill switch requires the 'full'/'ipv4'/'ipv6' wg-quick routing mode")
+	}
+	if routing.Mode == "ipv4" || routing.Mode == "ipv6" {
+		if options.Family != "both" && options.Family != routing.Mode {
+			return options, errors.New("wg-quick routing mode " + routing.Mode + " conflicts with address family " + options.Family)
+		}
+		options.Family = routing.Mode
+		return NormalizeOptions(options)
+	}
+	return options, nil
+}
+
+// interfaceLines renders the [Interface] routing fields
+func (routing WgQuickRouting) interfaceLines(resStruct C.Response, options C.GenerateOptions, host string, port int) string {
+	switch routing.Mode {
+	case "fwmark", "policy":
+	default:
+		if routing.KillSwitch {
+			return "\n" + routing.killSwitchLines(options, host, port)
+		}
+		return ""
+	}
+	table := strconv.FormatUint(uint64(routing.Table), 10)
+	mark := strconv.FormatUint(uint64(routing.FwMark), 10)
+
+	var ipCommands []string
+	if hasIPv4(options) {
+		ipCommands = append(ipCommands, "ip")
+	}
+	if hasIPv6(options) {
+		ipCommands = append(ipCommands, "ip -6")
+	}
+	rules := func(selector func(ip string) string) (lines string) {
+		for _, ip := range ipCommands {
+			lines += "PreUp = " + ip + " rule add " + selector(ip) + " lookup " + table + "\n"
+			lines += "PostDown = " + ip + " rule del " + selector(ip) + " lookup " + table + "\n"
+		}
+		return
+	}
+
+	lines := "\nTable = " + table + "\n\n" + rules(func(string) string { return "oif %i" }) + "\n"
+	if routing.Mode == "policy" {
+		return lines + rules(func(ip string) string {
+			return "from " + Ternary(ip == "ip", resStruct.Config.Interface.Addresses.V4+"/32", resStruct.Config.Interface.Addresses.V6+"/128")
+		})
+	}
+	return lines + rules(func(string) string { return "fwmark " + mark }) + "\n" + routing.markLines(resStruct, options, mark)
+}
+
+// markLines renders the PostUp/PreDown rules marking packets from the tunnel addresses
+func (routing WgQuickRouting) markLines(resStruct C.Response, options C.GenerateOptions, mark string) (lines string) {
+	v4 := resStruct.Config.Interface.Addresses.V4
+	v6 := resStruct.Config.Interface.Addresses.V6
+	if routing.Firewall == "nftables" {
+		table := "inet wgcf_" + mark
+		var marks []string
+		if hasIPv4(options) {
+			marks = append(marks, "nft add rule "+table+" output ip saddr "+v4+" meta mark set "+mark)
+		}
+		if hasIPv6(options) {
+			marks = append(marks, "nft add rule "+table+" output ip6 saddr "+v6+" meta mark set "+mark)
+		}
+		return "PostUp = nft add table " + table + "; nft add chain " + table + " output '{ type route hook output priority mangle; }'; " + strings.Join(marks, "; ") + "\n" +
+			"PreDown = nft delete table " + table + "\n"
+	}
+
+	if hasIPv4(options) {
+		lines += "PostUp = iptables -t mangle -A OUTPUT -s " + v4 + " -j MARK --set-mark " + mark + "\n"
+		lines += "PreDown = iptables -t mangle -D OUTPUT -s " + v4 + " -j MARK --set-mark " + mark + "\n"
+	}
+	if hasIPv6(options) {
+		lines += "PostUp = ip6tables -t mangle -A OUTPUT -s " + v6 + " -j MARK --set-mark " + mark + "\n"
+		lines += "PreDown = ip6tables -t mangle -D OUTPUT -s " + v6 + " -j MARK --set-mark " + mark + "\n"
+	}
+	return
+}
+
+// killSwitchExemptions are the destinations which stay reachable outside of
+// the tunnel: the LAN, multicast, broadcast and everything of the enabled
+// families not in AllowedIPs. A disabled family is rejected except for the LAN
+func killSwitchExemptions(options C.GenerateOptions) (v4 []string, v6 []string) {
+	var allowed, exempt []netip.Prefix
+	for _, cidr := range options.AllowedIPs {
+		if prefix, err := ParsePrefix(cidr); err == nil {
+			allowed = append(allowed, prefix)
+		}
+	}
+	for _, cidr := range append(slices.Clone(privateCIDRs), "224.0.0.0/4", "255.255.255.255/32", "ff00::/8") {
+		if prefix, err := ParsePrefix(cidr); err == nil {
+			exempt = append(exempt, prefix)
+		}
+	}
+	var everything []netip.Prefix
+	if hasIPv4(options) {
+		everything = append(everything, netip.MustParsePrefix("0.0.0.0/0"))
+	}
+	if hasIPv6(options) {
+		everything = append(everything, netip.MustParsePrefix("::/0"))
+	}
+	exempt = append(exempt, SubtractPrefixes(everything, allowed)...)
+
+	for _, prefix := range SubtractPrefixes(exempt, nil) {
+		if prefix.Addr().Is4() {
+			v4 = append(v4, prefix.String())
+		} else {
+			v6 = append(v6, prefix.String())
+		}
+	}
+	return
+}
+
+// killSwitchLines renders the PostUp/PreDown rules of the kill switch for both
+// address families, the rules live in a table or chain named after the
+// interface, so that several tunnels can each have their own kill switch
+func (routing WgQuickRouting) killSwitchLines(options C.GenerateOptions, host string, port int) (lines string) {
+	endpoint, err := netip.ParseAddr(host)
+	portStr := strconv.Itoa(port)
+	exemptV4, exemptV6 := killSwitchExemptions(options)
+	// a family whose every destination is exempt has nothing to reject
+	rejectV4 := !slices.Contains(exemptV4, "0.0.0.0/0")
+	rejectV6 := !slices.Contains(exemptV6, "::/0")
+	if !rejectV4 && !rejectV6 {
+		return
+	}
+	if err != nil {
+		lines += "# The endpoint is a domain, so WireGuard traffic to any host on UDP port " + portStr + " is allowed\n"
+	}
+
+	if routing.Firewall == "nftables" {
+		const table = "inet wgcf_killswitch_%i"
+		endpointRule := "udp dport " + portStr + " accept"
+		if err == nil {
+			endpointRule = Ternary(endpoint.Is4(), "ip", "ip6") + " daddr " + endpoint.String() + " " + endpointRule
+		}
+		lines += "PostUp = nft add table " + table + "\n"
+		lines += "PostUp = nft add chain " + table + " output '{ type filter hook output priority filter; policy accept; }'\n"
+		lines += "PostUp = nft add rule " + table + " output oifname { lo, %i } accept\n"
+		lines += "PostUp = nft add rule " + table + " output " + endpointRule + "\n"
+		if len(exemptV4) > 0 {
+			lines += "PostUp = nft add rule " + table + " output ip daddr { " + strings.Join(exemptV4, ", ") + " } accept\n"
+		}
+		if len(exemptV6) > 0 {
+			lines += "PostUp = nft add rule " + table + " output ip6 daddr { " + strings.Join(exemptV6, ", ") + " } accept\n"
+		}
+		rejectRule := "reject"
+		if !rejectV4 || !rejectV6 {
+			rejectRule = "meta nfproto " + Ternary(rejectV4, "ipv4", "ipv6") + " reject"
+		}
+		lines += "PostUp = nft add rule " + table + " output " + rejectRule + "\n"
+		lines += "PreDown = nft delete table " + table + "\n"
+		return
+	}
+
+	// iptables chain names are limited to 28 characters, enough for a 15 characters interface name
+	const chain = "wgcf-ks-%i"
+	for _, family := range []struct {
+		command string
+		is4     bool
+		exempt  []string
+		reject  bool
+	}{{"iptables", true, exemptV4, rejectV4}, {"ip6tables", false, exemptV6, rejectV6}} {
+		if !family.reject {
+			continue
+		}
+		lines += "PostUp = " + family.command + " -N " + chain + "\n"
+		lines += "PostUp = " + family.command + " -A " + chain + " -o lo -j RETURN\n"
+		lines += "PostUp = " + family.command + " -A " + chain + " -o %i -j RETURN\n"
+		if err != nil {
+			lines += "PostUp = " + family.command + " -A " + chain + " -p udp --dport " + portStr + " -j RETURN\n"
+		} else if endpoint.Is4() == family.is4 {
+			lines += "PostUp = " + family.command + " -A " + chain + " -d " + endpoint.String() + " -p udp --dport " + portStr + " -j RETURN\n"
+		}
+		for _, cidr := range family.exempt {
+			lines += "PostUp = " + family.command + " -A " + chain + " -d " + cidr + " -j RETURN\n"
+		}
+		lines += "PostUp = " + family.command + " -A " + chain + " -j REJECT\n"
+		lines += "PostUp = " + family.command + " -I OUTPUT -j " + chain + "\n"
+		lines += "PreDown = " + family.command + " -D OUTPUT -j " + chain + "\n"
+		lines += "PreDown = " + family.command + " -F " + chain + "\n"
+		lines += "PreDown = " + family.command + " -X " + chain + "\n"
+	}
+	return
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKillSwitchExemptions(t *testing.T) {
+	options := testOptions(t, nil)
+	routing := DefaultWgQuickRouting()
+	routing.Mode, routing.KillSwitch = "ipv4", true
+	options, err := routing.Normalize(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v4, v6 := killSwitchExemptions(options)
+	if slices.Contains(v6, "::/0") || !slices.Contains(v6, "fe80::/10") {
+		t.Errorf("Expected IPv6 to be rejected except for the LAN in ipv4 mode, but got: %v", v6)
+	}
+	if slices.Contains(v4, "0.0.0.0/0") || !slices.Contains(v4, "192.168.0.0/16") {
+		t.Errorf("Expected IPv4 to be rejected except for the LAN, but got: %v", v4)
+	}
+
+	for _, firewall := range WgQuickFirewalls {
+		routing.Firewall = firewall
+		lines := routing.killSwitchLines(options, "162.159.192.1", 2408)
+		if strings.Contains(lines, "::/0") || !strings.Contains(lines, "ip6") {
+			t.Errorf("Expected an IPv6 reject rule with %s, but got:\n%s", firewall, lines)
+		}
+	}
+}